@@ -0,0 +1,165 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// Add-ons
+const (
+	RoutingAddOn    string = "Routing"
+	DIAddOn         string = "Dependency injection"
+	NetworkingAddOn string = "Networking"
+	TestsAddOn      string = "Tests"
+	CIAddOn         string = "CI"
+	LintsAddOn      string = "Lints"
+)
+
+// List of add-ons
+var addOns = []string{
+	RoutingAddOn,
+	DIAddOn,
+	NetworkingAddOn,
+	TestsAddOn,
+	CIAddOn,
+	LintsAddOn,
+}
+
+var addOnDescriptions = map[string]string{
+	RoutingAddOn:    "named routes in lib/routes.dart, passed to the app",
+	DIAddOn:         "get_it container in lib/injection_container.dart",
+	NetworkingAddOn: "http API client, registered with get_it when selected",
+	TestsAddOn:      "mocktail and a pumpApp test helper",
+	CIAddOn:         "GitHub Actions, GitLab CI or shell script pipelines",
+	LintsAddOn:      "lint preset for analysis_options.yaml",
+}
+
+// ownDIStateManagers wire their dependencies themselves, Stacked with the
+// locator stacked_generator writes and flutter_modular with the binds of its
+// modules. The dependency injection add-on is not offered with them, it
+// would add a second container.
+var ownDIStateManagers = map[string]bool{
+	Stacked:        true,
+	FlutterModular: true,
+}
+
+// availableAddOns returns the add-ons offered with the state manager.
+func availableAddOns(stateManager string) []string {
+	var available []string
+	for _, addOn := range addOns {
+		if addOn == DIAddOn && ownDIStateManagers[stateManager] {
+			continue
+		}
+		available = append(available, addOn)
+	}
+	return available
+}
+
+// hasAddOn reports whether the add-on was selected.
+func hasAddOn(opts projectOptions, addOn string) bool {
+	if addOn == DIAddOn && ownDIStateManagers[opts.StateManager] {
+		return false
+	}
+	for _, selected := range opts.AddOns {
+		if selected == addOn {
+			return true
+		}
+	}
+	return false
+}
+
+// addOnPackages returns the packages and dev packages of the selected
+// add-ons.
+func addOnPackages(opts projectOptions) (packages, devPackages []string) {
+	if hasAddOn(opts, DIAddOn) && opts.Pattern != CleanArchitecture {
+		packages = append(packages, "get_it")
+	}
+	if hasAddOn(opts, NetworkingAddOn) {
+		packages = append(packages, "http")
+	}
+	if hasAddOn(opts, TestsAddOn) {
+		devPackages = append(devPackages, "mocktail")
+		if opts.StateManager == BLoC || opts.StateManager == Cubit {
+			devPackages = append(devPackages, "bloc_test")
+		}
+	}
+	return packages, devPackages
+}
+
+// renderAddOns adds the files of the selected add-ons to the rendered
+// architecture and wires them into main.dart.
+func renderAddOns(packageName string, opts projectOptions, files []generatedFile) []generatedFile {
+	uri := func(file string) string {
+		return "package:" + packageName + "/" + file
+	}
+	add := func(filePath, content string) {
+		files = append(files, generatedFile{Path: filePath, Content: sortDartImports(strings.TrimLeft(content, "\n"))})
+	}
+	patchMain := func(patch func(main string) string) {
+		for i, file := range files {
+			if file.Path == "lib/main.dart" {
+				files[i].Content = patch(file.Content)
+			}
+		}
+	}
+
+	// Clean Architecture already registers its layers with get_it
+	di := opts.Pattern == CleanArchitecture
+	if hasAddOn(opts, DIAddOn) && !di {
+		add("lib/injection_container.dart", `
+import 'package:get_it/get_it.dart';
+
+final sl = GetIt.instance;
+
+void init() {}
+`)
+		patchMain(func(main string) string {
+			main, _ = withMainInit(main, uri("injection_container.dart"))
+			return main
+		})
+		di = true
+	}
+
+	if hasAddOn(opts, NetworkingAddOn) {
+		clientDir := "api"
+		if opts.Pattern == CleanArchitecture {
+			clientDir = "core/network"
+		}
+		clientFile := path.Join(clientDir, "api_client.dart")
+		add(path.Join("lib", clientFile), strings.ReplaceAll(apiClientTemplate, "{{baseUrl}}", "https://api.example.com"))
+		if di {
+			for i, file := range files {
+				if file.Path == "lib/injection_container.dart" {
+					files[i].Content, _ = withRegistration(file.Content, "sl.registerLazySingleton(ApiClient.new);", uri(clientFile))
+				}
+			}
+		}
+	}
+
+	// flutter_modular projects declare their routes in their modules
+	if hasAddOn(opts, RoutingAddOn) && opts.StateManager != FlutterModular {
+		add("lib/routes.dart", `
+import 'package:flutter/widgets.dart';
+
+const appRoutes = <String, WidgetBuilder>{};
+`)
+		patchMain(func(main string) string {
+			main, _ = withAppRoutes(main, uri("routes.dart"))
+			return main
+		})
+	}
+
+	if hasAddOn(opts, TestsAddOn) {
+		add("test/helpers/pump_app.dart", `
+import 'package:flutter/material.dart';
+import 'package:flutter_test/flutter_test.dart';
+
+extension PumpApp on WidgetTester {
+  Future<void> pumpApp(Widget widget) {
+    return pumpWidget(MaterialApp(home: widget));
+  }
+}
+`)
+	}
+	return files
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDIAddOnWithOwnContainer(t *testing.T) {
+	for _, stateManager := range []string{Stacked, FlutterModular} {
+		opts := projectOptions{
+			ProjectName:  "demo",
+			Pattern:      patterns[0],
+			StateManager: stateManager,
+			AddOns:       []string{DIAddOn, NetworkingAddOn},
+		}
+		for _, addOn := range availableAddOns(stateManager) {
+			if addOn == DIAddOn {
+				t.Errorf("%s: the dependency injection add-on is offered", stateManager)
+			}
+		}
+		if packages, _ := addOnPackages(opts); strings.Join(packages, ",") != "http" {
+			t.Errorf("%s: add-on packages = %v, want [http]", stateManager, packages)
+		}
+		rendered, err := renderArchitecture("demo", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range rendered {
+			if file.Path == "lib/injection_container.dart" {
+				t.Errorf("%s: a get_it container is added next to the one of the state manager", stateManager)
+			}
+			if file.Path == "lib/main.dart" && strings.Contains(file.Content, "injection_container.dart") {
+				t.Errorf("%s: main.dart initializes a get_it container:\n%s", stateManager, file.Content)
+			}
+		}
+	}
+
+	opts := projectOptions{ProjectName: "demo", Pattern: patterns[0], StateManager: Provider, AddOns: []string{DIAddOn}}
+	rendered, err := renderArchitecture("demo", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, file := range rendered {
+		found = found || file.Path == "lib/injection_container.dart"
+	}
+	if !found {
+		t.Error("Provider: the get_it container is missing")
+	}
+}
@@ -0,0 +1,623 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// Layout patterns
+const (
+	Simple            string = "Simple (one folder per state management layer)"
+	Mvvm              string = "MVVM (Model-View-ViewModel)"
+	Mvc               string = "MVC (Model-View-Controller)"
+	CleanArchitecture string = "Clean Architecture"
+)
+
+// State managers
+const (
+	BLoC              string = "BLoC (Business Logic Component)"
+	Cubit             string = "Cubit"
+	Provider          string = "Provider"
+	Riverpod          string = "Riverpod"
+	GetX              string = "GetX"
+	MobX              string = "MobX"
+	Redux             string = "Redux"
+	ScopedModel       string = "Scoped Model"
+	StatesRebuilder   string = "States Rebuilder"
+	MvcPattern        string = "mvc_pattern"
+	Stacked           string = "Stacked"
+	FlutterModular    string = "flutter_modular"
+	RiverpodGenerator string = "Riverpod Generator"
+	HooksRiverpod     string = "hooks_riverpod"
+	Signals           string = "Signals"
+	ReduxThunk        string = "Redux Thunk"
+	AsyncRedux        string = "async_redux"
+)
+
+// List of layout patterns
+var patterns = []string{
+	Simple,
+	Mvvm,
+	Mvc,
+	CleanArchitecture,
+}
+
+// List of state managers
+var stateManagers = []string{
+	BLoC,
+	Cubit,
+	Provider,
+	Riverpod,
+	GetX,
+	MobX,
+	Redux,
+	ScopedModel,
+	StatesRebuilder,
+	MvcPattern,
+	Stacked,
+	FlutterModular,
+	RiverpodGenerator,
+	HooksRiverpod,
+	Signals,
+	ReduxThunk,
+	AsyncRedux,
+}
+
+// Descriptions shown next to the layout patterns and state managers
+var patternDescriptions = map[string]string{
+	Simple:            "state holders in their own folder next to main.dart",
+	Mvvm:              "views bound to view models backed by a repository",
+	Mvc:               "views driven by controllers updating a model",
+	CleanArchitecture: "features split into domain, data and presentation layers, wired with get_it",
+}
+
+var stateManagerDescriptions = map[string]string{
+	BLoC:              "events in, states out, with flutter_bloc",
+	Cubit:             "methods emitting states, with flutter_bloc",
+	Provider:          "ChangeNotifier classes provided down the widget tree",
+	Riverpod:          "compile-safe providers with flutter_riverpod",
+	GetX:              "reactive controllers and dependency management with get",
+	MobX:              "observables and actions generated by mobx_codegen",
+	Redux:             "a single store updated by reducers and middleware",
+	ScopedModel:       "Model classes provided down the widget tree",
+	StatesRebuilder:   "injected state listened to with states_rebuilder",
+	MvcPattern:        "ControllerMVC classes with mvc_pattern",
+	Stacked:           "views and view models with routes and a locator generated by stacked_generator",
+	FlutterModular:    "modules binding the stores and declaring the routes of each feature",
+	RiverpodGenerator: "@riverpod notifiers and providers generated by riverpod_generator",
+	HooksRiverpod:     "notifier providers watched by HookConsumerWidgets using flutter_hooks",
+	Signals:           "stores of signals and computed values rebuilt with Watch widgets",
+	ReduxThunk:        "an AppState with typed actions, combined reducers, thunks and selectors",
+	AsyncRedux:        "actions reducing an AppState synchronously or asynchronously with async_redux",
+}
+
+// generatedFile is a file of the sample an architecture generates, its path is
+// relative to the project root and uses forward slashes.
+type generatedFile struct {
+	Path    string
+	Content string
+}
+
+// sampleDependency is an object the state holder is constructed with.
+type sampleDependency struct {
+	Type string
+	Name string
+	URI  string
+}
+
+// Var is the name of the function providing the dependency, riverpod_generator
+// names its provider after it.
+func (d sampleDependency) Var() string {
+	return lowerFirst(d.Type)
+}
+
+// sampleData is what the Dart templates are rendered with, it describes the
+// counter sample for one layout pattern and state manager.
+type sampleData struct {
+	Package      string
+	Pattern      string
+	StateManager string
+	Title        string
+
+	// The state holder class and the file declaring it
+	Holder     string
+	HolderFile string
+	HolderURI  string
+
+	// The page showing the counter, in main.dart for the Simple layout
+	Page    string
+	PageURI string
+
+	Deps  []sampleDependency
+	Async bool
+	DI    bool
+	// Freezed is set when models are generated with freezed and
+	// json_serializable
+	Freezed bool
+
+	// StateClass is set when the state holder keeps an immutable
+	// CounterState generated by freezed instead of an int
+	StateClass bool
+	StateURI   string
+
+	// Dart expressions computing the counter, with {value} standing for
+	// the current count and {_} for the prefix of the dependencies
+	next string
+	load string
+	// Imports needed to construct the dependencies
+	wiringImports []string
+}
+
+// stateManagerSpec describes how a state manager is added to a project.
+type stateManagerSpec struct {
+	Packages    []string
+	DevPackages []string
+	// Dir is the folder of the state holder in the Simple and Clean layouts
+	Dir string
+	// Suffix names the state holder, e.g. CounterBloc
+	Suffix string
+	// RoleNamed holders are named after the role the layout gives them,
+	// e.g. CounterViewModel for MVVM
+	RoleNamed bool
+	// ImmutableState holders replace their whole state on every change, it
+	// becomes a freezed class when models are generated with freezed
+	ImmutableState bool
+
+	Holder string
+	Page   string
+	App    string
+	// Files are the other files of the state manager, they replace the
+	// files of the layout pattern with the same path. {dir} in their path
+	// stands for the folder of the state holder.
+	Files []generatedFile
+}
+
+// architectureName returns the name of a pattern and state manager combination.
+func architectureName(pattern, stateManager string) string {
+	return shortName(pattern) + " + " + shortName(stateManager)
+}
+
+// shortName strips the explanation in parentheses from an option.
+func shortName(option string) string {
+	if i := strings.Index(option, " ("); i >= 0 {
+		return option[:i]
+	}
+	return option
+}
+
+func addArchitecture(projectPath string, opts projectOptions) {
+	spec, ok := stateManagerSpecs[opts.StateManager]
+	if !ok {
+		fmt.Printf("State manager %s is not supported yet.\n", opts.StateManager)
+		return
+	}
+
+	// Add necessary packages
+	packages := spec.Packages
+	if opts.Pattern == CleanArchitecture {
+		packages = append([]string{"get_it"}, packages...)
+	}
+	devPackages := spec.DevPackages
+	if opts.Freezed {
+		packages = append(packages, freezedPackages...)
+		devPackages = appendMissing(devPackages, freezedDevPackages...)
+	}
+	addOnPackages, addOnDevPackages := addOnPackages(opts)
+	packages = appendMissing(packages, addOnPackages...)
+	devPackages = appendMissing(devPackages, addOnDevPackages...)
+	cmd1 := exec.Command("flutter", append([]string{"pub", "add"}, packages...)...)
+	cmd1.Dir = projectPath
+	executeCommand(cmd1)
+
+	if len(devPackages) > 0 {
+		var args []string
+		for _, pkg := range devPackages {
+			args = append(args, "dev:"+pkg)
+		}
+		cmd2 := exec.Command("flutter", append([]string{"pub", "add"}, args...)...)
+		cmd2.Dir = projectPath
+		executeCommand(cmd2)
+	}
+
+	// Create example classes
+	files, err := renderArchitecture(filepath.Base(projectPath), opts)
+	if err != nil {
+		fmt.Println("Error rendering templates:", err)
+		return
+	}
+	for _, file := range files {
+		filePath := filepath.Join(projectPath, filepath.FromSlash(file.Path))
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		createFile(filePath, file.Content)
+	}
+
+	if usesCodegen(opts) {
+		cmd3 := exec.Command("dart", "run", "build_runner", "build", "--delete-conflicting-outputs")
+		cmd3.Dir = projectPath
+		executeCommand(cmd3)
+	}
+}
+
+// renderArchitecture renders the counter sample of the layout pattern and
+// state manager, with the files of the selected add-ons, in memory.
+func renderArchitecture(packageName string, opts projectOptions) ([]generatedFile, error) {
+	spec, ok := stateManagerSpecs[opts.StateManager]
+	if !ok {
+		return nil, fmt.Errorf("state manager %s is not supported", opts.StateManager)
+	}
+	data, err := newSampleData(packageName, opts, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []generatedFile
+	render := func(filePath, text string) error {
+		content, err := renderDartTemplate(text, data, map[string]string{"page": spec.Page})
+		if err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+		for i, file := range files {
+			if file.Path == filePath {
+				files[i].Content = content
+				return nil
+			}
+		}
+		files = append(files, generatedFile{Path: filePath, Content: content})
+		return nil
+	}
+
+	if err := render("lib/main.dart", spec.App); err != nil {
+		return nil, err
+	}
+	if err := render(path.Join("lib", data.HolderFile), spec.Holder); err != nil {
+		return nil, err
+	}
+	if data.StateClass {
+		if err := render(path.Join("lib", strings.TrimPrefix(data.StateURI, "package:"+packageName+"/")), freezedStateTemplate); err != nil {
+			return nil, err
+		}
+	}
+	if data.PageURI != "" {
+		if err := render(path.Join("lib", strings.TrimPrefix(data.PageURI, "package:"+packageName+"/")), "[[template \"page\" .]]"); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range patternFiles[opts.Pattern] {
+		if err := render(file.Path, file.Content); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range spec.Files {
+		filePath := strings.ReplaceAll(file.Path, "{dir}", path.Join("lib", path.Dir(data.HolderFile)))
+		if err := render(filePath, file.Content); err != nil {
+			return nil, err
+		}
+	}
+	return renderAddOns(packageName, opts, files), nil
+}
+
+func newSampleData(packageName string, opts projectOptions, spec stateManagerSpec) (*sampleData, error) {
+	pattern, stateManager := opts.Pattern, opts.StateManager
+	data := &sampleData{
+		Package:      packageName,
+		Pattern:      pattern,
+		StateManager: stateManager,
+		Freezed:      opts.Freezed,
+		Title:        "Flutter " + shortName(stateManager) + " Example",
+		next:         "{value} + 1",
+	}
+
+	dir := spec.Dir
+	pageDir := ""
+	switch pattern {
+	case Simple:
+		data.Page = "MyHomePage"
+	case Mvvm:
+		dir, pageDir = "viewmodel", "view"
+		data.Page = "CounterView"
+		data.Deps = []sampleDependency{{"CounterRepository", "repository", "model/counter_repository.dart"}}
+		data.next = "{_}repository.increment()"
+	case Mvc:
+		dir, pageDir = "controller", "view"
+		data.Page = "CounterView"
+		data.Deps = []sampleDependency{{"CounterModel", "model", "model/counter_model.dart"}}
+		data.next = "{_}model.increment()"
+	case CleanArchitecture:
+		dir = "features/counter/presentation/" + dir
+		pageDir = "features/counter/presentation/pages"
+		data.Page = "CounterPage"
+		data.Deps = []sampleDependency{
+			{"GetCounter", "getCounter", "features/counter/domain/usecases/get_counter.dart"},
+			{"IncrementCounter", "incrementCounter", "features/counter/domain/usecases/increment_counter.dart"},
+		}
+		data.Async = true
+		data.DI = true
+		data.load = "(await {_}getCounter(const NoParams())).value"
+		data.next = "(await {_}incrementCounter(const NoParams())).value"
+	default:
+		return nil, fmt.Errorf("layout pattern %s is not supported", pattern)
+	}
+	if pattern != Simple {
+		data.Title = "Flutter " + architectureName(pattern, stateManager) + " Example"
+	}
+
+	data.Holder = "Counter" + holderRole(pattern, stateManager, spec)
+	data.HolderFile = path.Join(dir, snakeCase(data.Holder)+".dart")
+	data.HolderURI = data.uri(data.HolderFile)
+	if opts.Freezed && spec.ImmutableState {
+		data.StateClass = true
+		data.StateURI = data.uri(path.Join(dir, "counter_state.dart"))
+	}
+	if pageDir != "" {
+		data.PageURI = data.uri(path.Join(pageDir, snakeCase(data.Page)+".dart"))
+	}
+
+	for _, dep := range data.Deps {
+		if !data.DI {
+			data.wiringImports = append(data.wiringImports, dep.URI)
+		}
+	}
+	return data, nil
+}
+
+// holderRole returns the suffix of the state holder class: the role the
+// layout pattern gives it when the state manager names holders after their
+// role, the suffix of the state manager otherwise.
+func holderRole(pattern, stateManager string, spec stateManagerSpec) string {
+	if !spec.RoleNamed {
+		return spec.Suffix
+	}
+	switch pattern {
+	case Mvvm:
+		return "ViewModel"
+	case Mvc:
+		return "Controller"
+	case CleanArchitecture:
+		if stateManager == ScopedModel {
+			// CounterModel is the data model of the Clean layout
+			return "ScopedModel"
+		}
+	}
+	return spec.Suffix
+}
+
+func (d *sampleData) uri(file string) string {
+	return "package:" + d.Package + "/" + file
+}
+
+// PageVar is the name of a variable holding the page, which is also the
+// name of its generated route.
+func (d *sampleData) PageVar() string {
+	return lowerFirst(d.Page)
+}
+
+// HolderBase is the file name of the state holder without its extension.
+func (d *sampleData) HolderBase() string {
+	return strings.TrimSuffix(path.Base(d.HolderFile), ".dart")
+}
+
+// SiblingURI returns the URI of a file in the folder of the state holder.
+func (d *sampleData) SiblingURI(file string) string {
+	return d.uri(path.Join(path.Dir(d.HolderFile), file))
+}
+
+// HasDeps reports whether the state holder is constructed with dependencies.
+func (d *sampleData) HasDeps() bool {
+	return len(d.Deps) > 0
+}
+
+// Var is the name of a local variable holding the state holder.
+func (d *sampleData) Var() string {
+	return string(unicode.ToLower(rune(d.Holder[0]))) + d.Holder[1:]
+}
+
+// Imports returns the import directives of the dependencies.
+func (d *sampleData) Imports() string {
+	var b strings.Builder
+	for _, dep := range d.Deps {
+		fmt.Fprintf(&b, "import '%s';\n", d.uri(dep.URI))
+	}
+	if d.DI {
+		fmt.Fprintf(&b, "import '%s';\n", d.uri("core/usecases/usecase.dart"))
+	}
+	return b.String()
+}
+
+// WiringImports returns the import directives needed to construct the state
+// holder, the dependency injection container is imported with the di prefix
+// when prefixed is set.
+func (d *sampleData) WiringImports(prefixed bool) string {
+	var b strings.Builder
+	for _, uri := range d.wiringImports {
+		fmt.Fprintf(&b, "import '%s';\n", d.uri(uri))
+	}
+	if d.DI {
+		if prefixed {
+			fmt.Fprintf(&b, "import '%s' as di;\n", d.uri("injection_container.dart"))
+		} else {
+			fmt.Fprintf(&b, "import '%s';\n", d.uri("injection_container.dart"))
+		}
+	}
+	return b.String()
+}
+
+// CtorParams returns the named parameters of the state holder constructor.
+func (d *sampleData) CtorParams() string {
+	if !d.HasDeps() {
+		return ""
+	}
+	var params []string
+	for _, dep := range d.Deps {
+		params = append(params, "required "+dep.Type+" "+dep.Name)
+	}
+	return "{" + strings.Join(params, ", ") + "}"
+}
+
+// Initializers returns the initializer list of the state holder constructor,
+// ending with the superclass constructor call when given.
+func (d *sampleData) Initializers(super string) string {
+	var inits []string
+	for _, dep := range d.Deps {
+		inits = append(inits, "_"+dep.Name+" = "+dep.Name)
+	}
+	if super != "" {
+		inits = append(inits, super)
+	}
+	if len(inits) == 0 {
+		return ""
+	}
+	return " : " + strings.Join(inits, ", ")
+}
+
+// Fields returns the fields holding the dependencies, followed by a blank
+// line.
+func (d *sampleData) Fields() string {
+	if !d.HasDeps() {
+		return ""
+	}
+	var b strings.Builder
+	for _, dep := range d.Deps {
+		fmt.Fprintf(&b, "  final %s _%s;\n", dep.Type, dep.Name)
+	}
+	return b.String() + "\n"
+}
+
+// Args returns the arguments constructing the state holder, the dependency
+// injection container being referenced with the given prefix.
+func (d *sampleData) Args(prefix string) string {
+	var args []string
+	for _, dep := range d.Deps {
+		if d.DI {
+			args = append(args, dep.Name+": "+prefix+"sl()")
+		} else {
+			args = append(args, dep.Name+": "+dep.Type+"()")
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// New returns an expression constructing the state holder, a tear-off when it
+// takes no argument.
+func (d *sampleData) New(prefix string) string {
+	if !d.HasDeps() {
+		return d.Holder + ".new"
+	}
+	return "() => " + d.Holder + "(" + d.Args(prefix) + ")"
+}
+
+// DepsType returns the type of a record holding the dependencies, Args
+// builds its fields.
+func (d *sampleData) DepsType() string {
+	var fields []string
+	for _, dep := range d.Deps {
+		fields = append(fields, dep.Type+" "+dep.Name)
+	}
+	return "({" + strings.Join(fields, ", ") + "})"
+}
+
+// Next returns the expression computing the next count from value inside the
+// state holder.
+func (d *sampleData) Next(value string) string {
+	return strings.NewReplacer("{value}", value, "{_}", "_").Replace(d.next)
+}
+
+// NextParam is Next for code receiving the dependencies as parameters.
+func (d *sampleData) NextParam(value string) string {
+	return strings.NewReplacer("{value}", value, "{_}", "").Replace(d.next)
+}
+
+// NextVia is Next for code reaching the dependencies through a record.
+func (d *sampleData) NextVia(deps, value string) string {
+	return strings.NewReplacer("{value}", value, "{_}", deps+".").Replace(d.next)
+}
+
+// Load returns the expression loading the initial count inside the state
+// holder.
+func (d *sampleData) Load() string {
+	return strings.ReplaceAll(d.load, "{_}", "_")
+}
+
+// LoadParam is Load for code receiving the dependencies as parameters.
+func (d *sampleData) LoadParam() string {
+	return strings.ReplaceAll(d.load, "{_}", "")
+}
+
+// LoadVia is Load for code reaching the dependencies through a record.
+func (d *sampleData) LoadVia(deps string) string {
+	return strings.ReplaceAll(d.load, "{_}", deps+".")
+}
+
+// renderDartTemplate executes a Dart template, tidies its blank lines and
+// sorts its imports. Dart templates use [[ ]] as delimiters so that they do
+// not clash with string interpolation.
+// Partials are parsed as named templates the main one can include.
+func renderDartTemplate(text string, data interface{}, partials map[string]string) (string, error) {
+	tmpl, err := template.New("dart").Delims("[[", "]]").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	for name, partial := range partials {
+		if _, err := tmpl.New(name).Parse(partial); err != nil {
+			return "", err
+		}
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return sortDartImports(tidyBlankLines(b.String())), nil
+}
+
+// tidyBlankLines collapses runs of blank lines and removes the ones opening or
+// closing a block, leaving the templates free to use blank lines around their
+// conditional sections.
+func tidyBlankLines(content string) string {
+	lines := strings.Split(strings.Trim(content, "\n"), "\n")
+	var tidy []string
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(tidy) == 0 || tidy[len(tidy)-1] == "" || strings.HasSuffix(tidy[len(tidy)-1], "{") {
+				continue
+			}
+			if next := nextNonBlank(lines[i+1:]); strings.HasPrefix(next, "}") {
+				continue
+			}
+			line = ""
+		}
+		tidy = append(tidy, line)
+	}
+	return strings.Join(tidy, "\n") + "\n"
+}
+
+// nextNonBlank returns the first non-blank line, trimmed.
+func nextNonBlank(lines []string) string {
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+// snakeCase converts a Dart class name to a file name.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	GitHubActions string = "GitHub Actions"
+	GitLabCI      string = "GitLab CI"
+	ShellScript   string = "Shell script (ci.sh)"
+)
+
+// List of CI providers
+var ciProviders = []string{
+	GitHubActions,
+	GitLabCI,
+	ShellScript,
+}
+
+// ciStep is a single named command run by every pipeline.
+type ciStep struct {
+	Name    string
+	Command string
+}
+
+// ciSteps returns the commands the pipelines run for the given options, in
+// order. Formatting is checked before code generation so that build_runner
+// outputs, which are not committed, never fail the check.
+func ciSteps(opts projectOptions) []ciStep {
+	if opts.Monorepo {
+		return melosCISteps(opts)
+	}
+
+	steps := []ciStep{
+		{"Install dependencies", "flutter pub get"},
+		{"Check formatting", "dart format --output=none --set-exit-if-changed ."},
+	}
+	if usesCodegen(opts) {
+		steps = append(steps, ciStep{"Generate code", "dart run build_runner build --delete-conflicting-outputs"})
+	}
+	steps = append(steps, ciStep{"Analyze", "flutter analyze"})
+	// flutter analyze does not report the lints of analyzer plugins
+	if len(analyzerPlugins(opts)) > 0 {
+		steps = append(steps, ciStep{"Run custom lints", "dart run custom_lint"})
+	}
+	steps = append(steps, ciStep{"Run tests", "flutter test --coverage"})
+	return steps
+}
+
+// melosCISteps runs the same checks through the scripts of the melos
+// workspace, in every package.
+func melosCISteps(opts projectOptions) []ciStep {
+	steps := []ciStep{
+		{"Install dependencies", "dart pub get"},
+		{"Bootstrap workspace", "dart run melos bootstrap"},
+		{"Check formatting", "dart run melos run format --no-select"},
+	}
+	if usesCodegen(opts) {
+		steps = append(steps, ciStep{"Generate code", "dart run melos run build_runner --no-select"})
+	}
+	steps = append(steps,
+		ciStep{"Analyze", "dart run melos run analyze --no-select"},
+		ciStep{"Run tests", "dart run melos run test --no-select"},
+	)
+	return steps
+}
+
+// coveragePath returns the coverage reports the pipelines keep as artifacts.
+func coveragePath(opts projectOptions) string {
+	if opts.Monorepo {
+		return "**/coverage/lcov.info"
+	}
+	return "coverage/lcov.info"
+}
+
+func addCIPipelines(projectPath string, opts projectOptions) {
+	steps := ciSteps(opts)
+
+	for _, provider := range opts.CIProviders {
+		switch provider {
+		case GitHubActions:
+			addGitHubActions(projectPath, steps, coveragePath(opts))
+		case GitLabCI:
+			addGitLabCI(projectPath, steps, coveragePath(opts))
+		case ShellScript:
+			addCIScript(projectPath, steps)
+		default:
+			fmt.Printf("CI provider %s is not supported yet.\n", provider)
+		}
+	}
+}
+
+func addGitHubActions(projectPath string, steps []ciStep, coverage string) {
+	var b strings.Builder
+	b.WriteString(`name: CI
+
+on:
+  push:
+    branches: [main]
+  pull_request:
+
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: subosito/flutter-action@v2
+        with:
+          channel: stable
+          cache: true
+`)
+	for _, step := range steps {
+		fmt.Fprintf(&b, "      - name: %s\n        run: %s\n", step.Name, step.Command)
+	}
+	fmt.Fprintf(&b, `      - uses: actions/upload-artifact@v4
+        with:
+          name: coverage
+          path: "%s"
+`, coverage)
+
+	os.MkdirAll(filepath.Join(projectPath, ".github", "workflows"), 0755)
+	createFile(filepath.Join(projectPath, ".github", "workflows", "ci.yaml"), b.String())
+}
+
+func addGitLabCI(projectPath string, steps []ciStep, coverage string) {
+	var b strings.Builder
+	b.WriteString(`image: ghcr.io/cirruslabs/flutter:stable
+
+stages:
+  - build
+
+build:
+  stage: build
+  script:
+`)
+	for _, step := range steps {
+		fmt.Fprintf(&b, "    # %s\n    - %s\n", step.Name, step.Command)
+	}
+	fmt.Fprintf(&b, `  artifacts:
+    paths:
+      - "%s"
+`, coverage)
+
+	createFile(filepath.Join(projectPath, ".gitlab-ci.yml"), b.String())
+}
+
+func addCIScript(projectPath string, steps []ciStep) {
+	var b strings.Builder
+	b.WriteString(`#!/usr/bin/env sh
+set -eu
+
+cd "$(dirname "$0")"
+`)
+	for _, step := range steps {
+		fmt.Fprintf(&b, "\necho \"==> %s\"\n%s\n", step.Name, step.Command)
+	}
+
+	scriptPath := filepath.Join(projectPath, "ci.sh")
+	createFile(scriptPath, b.String())
+	if err := os.Chmod(scriptPath, 0755); err != nil {
+		fmt.Println("Error making ci.sh executable:", err)
+	}
+}
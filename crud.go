@@ -0,0 +1,607 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// crudField is a field of a CRUD entity, next to its id.
+type crudField struct {
+	Name     string
+	Type     string
+	Nullable bool
+}
+
+// crudFieldTypes are the field types the form page knows how to edit.
+var crudFieldTypes = map[string]bool{
+	"String": true,
+	"int":    true,
+	"double": true,
+	"num":    true,
+	"bool":   true,
+}
+
+// crudData is the data of the CRUD templates.
+type crudData struct {
+	Package string
+	Entity  string
+	Plural  string
+	Fields  []crudField
+	Holder  string
+
+	ListPage   string
+	DetailPage string
+	FormPage   string
+
+	EntityURI     string
+	RepositoryURI string
+	InMemoryURI   string
+	HolderURI     string
+	ListURI       string
+	DetailURI     string
+	FormURI       string
+	ContainerURI  string
+}
+
+// crudSpec holds the templates of the state holder and of the list page of
+// a CRUD screen for a state manager. The list page defines the "save" and
+// "delete" templates the shared list and add button templates use.
+type crudSpec struct {
+	Holder string
+	List   string
+}
+
+func addCommand(args []string) {
+	if len(args) == 0 || args[0] != "crud" {
+		fmt.Println("Usage: flutter-arch add crud [flags] <Entity>")
+		return
+	}
+
+	flags := flag.NewFlagSet("add crud", flag.ExitOnError)
+	fieldList := flags.String("fields", "", "comma-separated fields of the entity, like name:String,price:double (types: String, int, double, num, bool, with ? when nullable)")
+	project := flags.String("project", ".", "path of the Flutter project")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch add crud [flags] <Entity>")
+		flags.PrintDefaults()
+	}
+	positional := parseInterspersed(flags, args[1:])
+	if len(positional) != 1 {
+		flags.Usage()
+		return
+	}
+
+	entity := positional[0]
+	if !isDartClassName(entity) {
+		fmt.Printf("Entity %s is not an UpperCamelCase class name.\n", entity)
+		return
+	}
+	fields, err := parseCrudFields(*fieldList)
+	if err != nil {
+		fmt.Println("Error parsing fields:", err)
+		return
+	}
+
+	projectPath := *project
+	pattern, stateManager, err := detectArchitecture(projectPath)
+	if err != nil {
+		fmt.Println("Error detecting the architecture:", err)
+		return
+	}
+	fmt.Printf("Adding %s CRUD screens for %s\n", entity, architectureName(pattern, stateManager))
+
+	data := newCrudData(pubspecName(projectPath), entity, fields, pattern, stateManager)
+	files, err := renderCrud(data, stateManager)
+	if err != nil {
+		fmt.Println("Error rendering the CRUD screens:", err)
+		return
+	}
+
+	// Add necessary packages for the dependency injection
+	cmd1 := exec.Command("flutter", "pub", "add", "get_it")
+	cmd1.Dir = projectPath
+	executeCommand(cmd1)
+
+	for _, file := range files {
+		filePath := filepath.Join(projectPath, filepath.FromSlash(file.Path))
+		if _, err := os.Stat(filePath); err == nil {
+			fmt.Printf("%s already exists, remove it to generate it again.\n", file.Path)
+			continue
+		}
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		createFile(filePath, file.Content)
+		fmt.Println("Created", file.Path)
+	}
+	registerDependency(projectPath,
+		"sl.registerLazySingleton<"+entity+"Repository>(InMemory"+entity+"Repository.new);",
+		data.RepositoryURI, data.InMemoryURI)
+	if stateManager == FlutterModular {
+		addModuleRoute(projectPath, data)
+	} else {
+		addRoute(projectPath, data)
+	}
+
+	if stateManager == MobX || stateManager == RiverpodGenerator {
+		cmd2 := exec.Command("dart", "run", "build_runner", "build", "--delete-conflicting-outputs")
+		cmd2.Dir = projectPath
+		executeCommand(cmd2)
+	}
+
+	cmd3 := exec.Command("dart", "format", "lib")
+	cmd3.Dir = projectPath
+	executeCommand(cmd3)
+
+	fmt.Printf("Open the list with Navigator.of(context).pushNamed(%s.routeName).\n", data.ListPage)
+}
+
+// parseCrudFields parses fields written as name:Type, comma-separated.
+func parseCrudFields(list string) ([]crudField, error) {
+	var fields []crudField
+	seen := map[string]bool{"id": true}
+	for _, spec := range strings.Split(list, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, fieldType, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("field %q has no type, write it name:Type", spec)
+		}
+		field := crudField{Name: dartFieldName(name), Type: strings.TrimSpace(fieldType)}
+		if strings.HasSuffix(field.Type, "?") {
+			field.Type = strings.TrimSuffix(field.Type, "?")
+			field.Nullable = true
+		}
+		if !crudFieldTypes[field.Type] {
+			return nil, fmt.Errorf("field %s has type %s, only String, int, double, num and bool are supported", name, field.Type)
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("field %s is defined twice or clashes with the id", field.Name)
+		}
+		seen[field.Name] = true
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields, pass them with --fields name:String,price:double")
+	}
+	return fields, nil
+}
+
+// detectArchitecture finds the layout pattern and the state manager of a
+// project from its folders and its dependencies.
+func detectArchitecture(projectPath string) (string, string, error) {
+	lib := filepath.Join(projectPath, "lib")
+	pattern := detectPattern(projectPath)
+
+	deps := make(map[string]bool)
+	for _, dep := range pubspecDependencies(projectPath, "dependencies") {
+		deps[dep] = true
+	}
+	// MobX projects depend on provider as well, so it comes last
+	switch {
+	case deps["hooks_riverpod"]:
+		return pattern, HooksRiverpod, nil
+	case deps["riverpod_annotation"]:
+		return pattern, RiverpodGenerator, nil
+	case deps["flutter_riverpod"]:
+		return pattern, Riverpod, nil
+	case deps["get"]:
+		return pattern, GetX, nil
+	case deps["mobx"]:
+		return pattern, MobX, nil
+	case deps["async_redux"]:
+		return pattern, AsyncRedux, nil
+	case deps["redux_thunk"]:
+		return pattern, ReduxThunk, nil
+	case deps["flutter_redux"]:
+		return pattern, Redux, nil
+	case deps["scoped_model"]:
+		return pattern, ScopedModel, nil
+	case deps["states_rebuilder"]:
+		return pattern, StatesRebuilder, nil
+	case deps["mvc_pattern"]:
+		return pattern, MvcPattern, nil
+	case deps["signals"]:
+		return pattern, Signals, nil
+	case deps["stacked"]:
+		return pattern, Stacked, nil
+	case deps["flutter_modular"]:
+		return pattern, FlutterModular, nil
+	case deps["flutter_bloc"]:
+		if dartSourceContains(lib, "extends Cubit<") {
+			return pattern, Cubit, nil
+		}
+		return pattern, BLoC, nil
+	case deps["provider"]:
+		return pattern, Provider, nil
+	}
+	return "", "", fmt.Errorf("no supported state manager in the dependencies of pubspec.yaml")
+}
+
+// detectPattern recognizes the layout pattern from the folders of lib/.
+func detectPattern(projectPath string) string {
+	isDir := func(name string) bool {
+		info, err := os.Stat(filepath.Join(projectPath, "lib", name))
+		return err == nil && info.IsDir()
+	}
+
+	switch {
+	case isDir("features"):
+		return CleanArchitecture
+	case isDir("viewmodel"):
+		return Mvvm
+	case isDir("view") && isDir("controller"):
+		return Mvc
+	}
+	return Simple
+}
+
+// dartSourceContains reports whether a Dart file under dir contains text.
+func dartSourceContains(dir, text string) bool {
+	found := false
+	filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || found || entry.IsDir() || !strings.HasSuffix(filePath, ".dart") {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err == nil && strings.Contains(string(content), text) {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// newCrudData places the CRUD files like the counter sample of the layout
+// pattern: in a feature for Clean Architecture, next to the model, view and
+// state holder folders otherwise.
+func newCrudData(packageName, entity string, fields []crudField, pattern, stateManager string) *crudData {
+	spec := stateManagerSpecs[stateManager]
+	data := &crudData{
+		Package: packageName,
+		Entity:  entity,
+		Plural:  plural(entity),
+		Fields:  fields,
+		Holder:  entity + holderRole(pattern, stateManager, spec),
+	}
+
+	page := "Page"
+	var modelDir, repositoryDir, dataDir, holderDir, pageDir string
+	switch pattern {
+	case Mvvm, Mvc:
+		page = "View"
+		modelDir, repositoryDir, dataDir, pageDir = "model", "model", "model", "view"
+		holderDir = "viewmodel"
+		if pattern == Mvc {
+			holderDir = "controller"
+		}
+	case CleanArchitecture:
+		feature := "features/" + snakeCase(data.Plural)
+		modelDir = feature + "/domain/entities"
+		repositoryDir = feature + "/domain/repositories"
+		dataDir = feature + "/data/repositories"
+		holderDir = feature + "/presentation/" + spec.Dir
+		pageDir = feature + "/presentation/pages"
+	default:
+		modelDir, repositoryDir, dataDir, pageDir = "models", "repository", "repository", "pages"
+		holderDir = spec.Dir
+	}
+	data.ListPage = entity + "List" + page
+	data.DetailPage = entity + "Detail" + page
+	data.FormPage = entity + "Form" + page
+
+	name := snakeCase(entity)
+	data.EntityURI = data.uri(path.Join(modelDir, name+".dart"))
+	data.RepositoryURI = data.uri(path.Join(repositoryDir, name+"_repository.dart"))
+	data.InMemoryURI = data.uri(path.Join(dataDir, "in_memory_"+name+"_repository.dart"))
+	data.HolderURI = data.uri(path.Join(holderDir, snakeCase(data.Holder)+".dart"))
+	data.ListURI = data.uri(path.Join(pageDir, snakeCase(data.ListPage)+".dart"))
+	data.DetailURI = data.uri(path.Join(pageDir, snakeCase(data.DetailPage)+".dart"))
+	data.FormURI = data.uri(path.Join(pageDir, snakeCase(data.FormPage)+".dart"))
+	data.ContainerURI = data.uri("injection_container.dart")
+	return data
+}
+
+// renderCrud renders the CRUD files of an entity in memory.
+func renderCrud(data *crudData, stateManager string) ([]generatedFile, error) {
+	spec, ok := crudSpecs[stateManager]
+	if !ok {
+		return nil, fmt.Errorf("state manager %s is not supported", stateManager)
+	}
+
+	templates := []struct{ uri, text string }{
+		{data.EntityURI, crudEntityTemplate},
+		{data.RepositoryURI, crudRepositoryTemplate},
+		{data.InMemoryURI, crudInMemoryRepositoryTemplate},
+		{data.HolderURI, spec.Holder},
+		{data.ListURI, spec.List},
+		{data.DetailURI, crudDetailTemplate},
+		{data.FormURI, crudFormTemplate},
+	}
+	var files []generatedFile
+	for _, t := range templates {
+		filePath := path.Join("lib", strings.TrimPrefix(t.uri, "package:"+data.Package+"/"))
+		content, err := renderDartTemplate(t.text, data, crudPartials)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
+		}
+		files = append(files, generatedFile{Path: filePath, Content: content})
+	}
+	return files, nil
+}
+
+// addRoute registers the list page in lib/routes.dart, creating it and
+// handing its routes to the MaterialApp of main.dart the first time.
+func addRoute(projectPath string, data *crudData) {
+	routesPath := filepath.Join(projectPath, "lib", "routes.dart")
+	entry := "  " + data.ListPage + ".routeName: " + data.Var() + "ListRoute,\n"
+	content, err := os.ReadFile(routesPath)
+	if os.IsNotExist(err) {
+		createFile(routesPath, sortDartImports(`import 'package:flutter/widgets.dart';
+import '`+data.ListURI+`';
+
+const appRoutes = <String, WidgetBuilder>{
+`+entry+`};
+`))
+		addAppRoutes(projectPath, data.uri("routes.dart"))
+		return
+	}
+	if err != nil {
+		fmt.Println("Error reading routes.dart:", err)
+		return
+	}
+
+	routes := string(content)
+	if strings.Contains(routes, entry) {
+		return
+	}
+	end := strings.LastIndex(routes, "};")
+	if end < 0 {
+		fmt.Printf("Could not find appRoutes in routes.dart, add %s to it.\n", strings.TrimSpace(entry))
+		return
+	}
+	if strings.HasSuffix(routes[:end], "{") {
+		entry = "\n" + entry
+	}
+	routes = routes[:end] + entry + routes[end:]
+	routes = "import '" + data.ListURI + "';\n" + routes
+	createFile(routesPath, sortDartImports(routes))
+}
+
+// addModuleRoute mounts the list page on a route of the app module of
+// flutter_modular projects, whose MaterialApp.router takes no routes map.
+func addModuleRoute(projectPath string, data *crudData) {
+	modulePath := filepath.Join(projectPath, "lib", "app", "app_module.dart")
+	entry := "r.child(" + data.ListPage + ".routeName, child: " + data.Var() + "ListRoute);"
+	content, err := os.ReadFile(modulePath)
+	if err != nil {
+		fmt.Println("Error reading app_module.dart:", err)
+		return
+	}
+
+	module := string(content)
+	if strings.Contains(module, entry) {
+		return
+	}
+	routes := moduleRoutesPattern.FindStringIndex(module)
+	if routes == nil {
+		fmt.Printf("Could not find the routes of AppModule in app_module.dart, add %s to them.\n", entry)
+		return
+	}
+	module = module[:routes[1]] + "    " + entry + "\n" + module[routes[1]:]
+	module = "import '" + data.ListURI + "';\n" + module
+	createFile(modulePath, sortDartImports(module))
+}
+
+// moduleRoutesPattern matches the first line of the routes method of a
+// flutter_modular module.
+var moduleRoutesPattern = regexp.MustCompile(`void routes\(RouteManager r\) \{\n`)
+
+// addAppRoutes passes the routes of routes.dart to the app of main.dart.
+func addAppRoutes(projectPath, routesURI string) {
+	mainPath := filepath.Join(projectPath, "lib", "main.dart")
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		fmt.Println("Error reading main.dart:", err)
+		return
+	}
+	main, ok := withAppRoutes(string(content), routesURI)
+	if !ok {
+		fmt.Println("Could not find MaterialApp in main.dart, pass it routes: appRoutes to open the CRUD pages.")
+		return
+	}
+	createFile(mainPath, main)
+}
+
+// withAppRoutes returns main.dart with the routes of routes.dart passed to
+// its MaterialApp, or GetMaterialApp, and false when it has none.
+func withAppRoutes(main, routesURI string) (string, bool) {
+	if strings.Contains(main, "routes: appRoutes") {
+		return main, true
+	}
+	at := strings.Index(main, "MaterialApp(")
+	if at < 0 {
+		return main, false
+	}
+	at += len("MaterialApp(")
+	lineStart := strings.LastIndex(main[:at], "\n") + 1
+	line := main[lineStart:at]
+	indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+	main = main[:at] + "\n" + indent + "  routes: appRoutes," + main[at:]
+	main = "import '" + routesURI + "';\n" + main
+	return sortDartImports(main), true
+}
+
+// plural returns the English plural of a class name.
+func plural(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+func (d *crudData) uri(file string) string {
+	return "package:" + d.Package + "/" + file
+}
+
+// Var is the name of a variable holding an entity.
+func (d *crudData) Var() string {
+	return lowerFirst(d.Entity)
+}
+
+// HolderBase is the file name of the state holder without its extension.
+func (d *crudData) HolderBase() string {
+	return strings.TrimSuffix(path.Base(d.HolderURI), ".dart")
+}
+
+// PluralVar is the name of a variable holding the list of entities.
+func (d *crudData) PluralVar() string {
+	return lowerFirst(d.Plural)
+}
+
+// HolderVar is the name of a variable holding the state holder.
+func (d *crudData) HolderVar() string {
+	return lowerFirst(d.Holder)
+}
+
+// Label is the entity name in a sentence.
+func (d *crudData) Label() string {
+	return strings.ToLower(strings.Join(jsonKeyWords(d.Entity), " "))
+}
+
+// PluralLabel is the title of the list page.
+func (d *crudData) PluralLabel() string {
+	return strings.Join(jsonKeyWords(d.Plural), " ")
+}
+
+// RouteName is the named route of the list page.
+func (d *crudData) RouteName() string {
+	return "/" + strings.ReplaceAll(snakeCase(d.Plural), "_", "-")
+}
+
+// TileTitle returns the text naming an entity held by a variable: its first
+// String field, its id if it has none.
+func (d *crudData) TileTitle(entity string) string {
+	for _, field := range d.Fields {
+		if field.Type == "String" && !field.Nullable {
+			return entity + "." + field.Name
+		}
+	}
+	return entity + ".id"
+}
+
+// CtorFields returns the fields in constructor order, required ones first.
+func (d *crudData) CtorFields() []crudField {
+	var required, optional []crudField
+	for _, field := range d.Fields {
+		if field.Nullable {
+			optional = append(optional, field)
+		} else {
+			required = append(required, field)
+		}
+	}
+	return append(required, optional...)
+}
+
+// DartType is the declared type of the field.
+func (f crudField) DartType() string {
+	if f.Nullable {
+		return f.Type + "?"
+	}
+	return f.Type
+}
+
+// IsBool reports whether the form edits the field with a switch.
+func (f crudField) IsBool() bool {
+	return f.Type == "bool"
+}
+
+// Label is the field name in a sentence.
+func (f crudField) Label() string {
+	label := strings.ToLower(strings.Join(jsonKeyWords(f.Name), " "))
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// Text returns the String showing the field of the entity held by a
+// variable.
+func (f crudField) Text(entity string) string {
+	value := entity + "." + f.Name
+	switch {
+	case f.Type == "String" && f.Nullable:
+		return value + " ?? ''"
+	case f.Type == "String":
+		return value
+	case f.IsBool() && f.Nullable:
+		return "(" + value + " ?? false) ? 'Yes' : 'No'"
+	case f.IsBool():
+		return value + " ? 'Yes' : 'No'"
+	case f.Nullable:
+		return value + "?.toString() ?? ''"
+	}
+	return value + ".toString()"
+}
+
+// InitialText returns the initial text of the form field, entity being a
+// nullable expression.
+func (f crudField) InitialText(entity string) string {
+	value := entity + "." + f.Name
+	if f.Type == "String" {
+		return value + " ?? ''"
+	}
+	if f.Nullable {
+		return value + "?.toString() ?? ''"
+	}
+	return value + ".toString() ?? ''"
+}
+
+// Value returns the expression reading the field back from the form.
+func (f crudField) Value() string {
+	text := "_" + f.Name + "Controller.text"
+	switch {
+	case f.IsBool():
+		return "_" + f.Name
+	case f.Type == "String" && f.Nullable:
+		return text + ".isEmpty ? null : " + text
+	case f.Type == "String":
+		return text
+	case f.Nullable:
+		return f.Type + ".tryParse(" + text + ")"
+	}
+	return f.Type + ".parse(" + text + ")"
+}
+
+// Keyboard returns the keyboard type of the form field, if not text.
+func (f crudField) Keyboard() string {
+	switch f.Type {
+	case "int":
+		return "TextInputType.number"
+	case "double", "num":
+		return "const TextInputType.numberWithOptions(decimal: true)"
+	}
+	return ""
+}
+
+// Validator returns the validator of the form field, if it needs one.
+func (f crudField) Validator() string {
+	if f.Type == "String" {
+		return ""
+	}
+	if f.Nullable {
+		return "(value) => value == null || value.isEmpty || " + f.Type + ".tryParse(value) != null ? null : 'Enter a number'"
+	}
+	return "(value) => " + f.Type + ".tryParse(value ?? '') == null ? 'Enter a number' : null"
+}
+
+func lowerFirst(name string) string {
+	return string(unicode.ToLower(rune(name[0]))) + name[1:]
+}
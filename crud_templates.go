@@ -0,0 +1,1578 @@
+package main
+
+// The entity, its repository, the detail page and the form page of a CRUD
+// screen are the same for every state manager: the form hands the edited
+// entity to an onSaved callback the list page binds to its state holder.
+
+const crudEntityTemplate = `
+class [[.Entity]] {
+  const [[.Entity]]({
+    required this.id,
+[[- range .CtorFields]]
+    [[if not .Nullable]]required [[end]]this.[[.Name]],
+[[- end]]
+  });
+
+  final String id;
+[[- range .Fields]]
+  final [[.DartType]] [[.Name]];
+[[- end]]
+
+  [[.Entity]] copyWith({
+    String? id,
+[[- range .Fields]]
+    [[.Type]]? [[.Name]],
+[[- end]]
+  }) {
+    return [[.Entity]](
+      id: id ?? this.id,
+[[- range .Fields]]
+      [[.Name]]: [[.Name]] ?? this.[[.Name]],
+[[- end]]
+    );
+  }
+}
+`
+
+const crudRepositoryTemplate = `
+import '[[.EntityURI]]';
+
+abstract interface class [[.Entity]]Repository {
+  Future<List<[[.Entity]]>> getAll();
+
+  Future<[[.Entity]]> save([[.Entity]] [[.Var]]);
+
+  Future<void> delete(String id);
+}
+`
+
+const crudInMemoryRepositoryTemplate = `
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class InMemory[[.Entity]]Repository implements [[.Entity]]Repository {
+  final _[[.PluralVar]] = <String, [[.Entity]]>{};
+  int _nextId = 1;
+
+  @override
+  Future<List<[[.Entity]]>> getAll() async => List.unmodifiable(_[[.PluralVar]].values);
+
+  @override
+  Future<[[.Entity]]> save([[.Entity]] [[.Var]]) async {
+    final saved = [[.Var]].id.isEmpty ? [[.Var]].copyWith(id: '${_nextId++}') : [[.Var]];
+    _[[.PluralVar]][saved.id] = saved;
+    return saved;
+  }
+
+  @override
+  Future<void> delete(String id) async {
+    _[[.PluralVar]].remove(id);
+  }
+}
+`
+
+const crudDetailTemplate = `
+import 'package:flutter/material.dart';
+import '[[.EntityURI]]';
+import '[[.FormURI]]';
+
+class [[.DetailPage]] extends StatelessWidget {
+  const [[.DetailPage]]({
+    required this.[[.Var]],
+    required this.onSaved,
+    super.key,
+  });
+
+  final [[.Entity]] [[.Var]];
+  final ValueChanged<[[.Entity]]> onSaved;
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: Text([[.TileTitle .Var]]),
+        actions: [
+          IconButton(
+            icon: const Icon(Icons.edit),
+            onPressed: () => Navigator.of(context).pushReplacement(
+              MaterialPageRoute<void>(
+                builder: (_) => [[.FormPage]]([[.Var]]: [[.Var]], onSaved: onSaved),
+              ),
+            ),
+          ),
+        ],
+      ),
+      body: ListView(
+        children: [
+[[- range .Fields]]
+          ListTile(
+            title: const Text('[[.Label]]'),
+            subtitle: Text([[.Text $.Var]]),
+          ),
+[[- end]]
+        ],
+      ),
+    );
+  }
+}
+`
+
+const crudFormTemplate = `
+import 'package:flutter/material.dart';
+import '[[.EntityURI]]';
+
+class [[.FormPage]] extends StatefulWidget {
+  const [[.FormPage]]({required this.onSaved, super.key, this.[[.Var]]});
+
+  final [[.Entity]]? [[.Var]];
+  final ValueChanged<[[.Entity]]> onSaved;
+
+  @override
+  State<[[.FormPage]]> createState() => _[[.FormPage]]State();
+}
+
+class _[[.FormPage]]State extends State<[[.FormPage]]> {
+  final _formKey = GlobalKey<FormState>();
+[[- range .Fields]]
+[[- if .IsBool]]
+  late bool _[[.Name]] = widget.[[$.Var]]?.[[.Name]] ?? false;
+[[- else]]
+  late final _[[.Name]]Controller = TextEditingController(
+    text: [[.InitialText (printf "widget.%s?" $.Var)]],
+  );
+[[- end]]
+[[- end]]
+
+  @override
+  void dispose() {
+[[- range .Fields]][[if not .IsBool]]
+    _[[.Name]]Controller.dispose();
+[[- end]][[end]]
+    super.dispose();
+  }
+
+  void _submit() {
+    if (!_formKey.currentState!.validate()) {
+      return;
+    }
+    widget.onSaved(
+      [[.Entity]](
+        id: widget.[[.Var]]?.id ?? '',
+[[- range .Fields]]
+        [[.Name]]: [[.Value]],
+[[- end]]
+      ),
+    );
+    Navigator.of(context).pop();
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: Text(widget.[[.Var]] == null ? 'New [[.Label]]' : 'Edit [[.Label]]'),
+      ),
+      body: Form(
+        key: _formKey,
+        child: ListView(
+          padding: const EdgeInsets.all(16),
+          children: [
+[[- range .Fields]]
+[[- if .IsBool]]
+            SwitchListTile(
+              title: const Text('[[.Label]]'),
+              value: _[[.Name]],
+              onChanged: (value) => setState(() => _[[.Name]] = value),
+            ),
+[[- else]]
+            TextFormField(
+              controller: _[[.Name]]Controller,
+              decoration: const InputDecoration(labelText: '[[.Label]]'),
+[[- with .Keyboard]]
+              keyboardType: [[.]],
+[[- end]]
+[[- with .Validator]]
+              validator: [[.]],
+[[- end]]
+            ),
+[[- end]]
+[[- end]]
+          ],
+        ),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: _submit,
+        child: const Icon(Icons.save),
+      ),
+    );
+  }
+}
+`
+
+// crudPartials are the parts of the list page shared by every state manager.
+// "list" expects the entities in a variable named after the plural.
+var crudPartials = map[string]string{
+	"list": `ListView.builder(
+        itemCount: [[.PluralVar]].length,
+        itemBuilder: (context, index) {
+          final [[.Var]] = [[.PluralVar]][index];
+          return ListTile(
+            title: Text([[.TileTitle .Var]]),
+            onTap: () => Navigator.of(context).push(
+              MaterialPageRoute<void>(
+                builder: (_) => [[.DetailPage]](
+                  [[.Var]]: [[.Var]],
+                  onSaved: [[template "save" .]],
+                ),
+              ),
+            ),
+            trailing: IconButton(
+              icon: const Icon(Icons.delete),
+              onPressed: () => [[template "delete" .]],
+            ),
+          );
+        },
+      )`,
+	"add": `FloatingActionButton(
+        onPressed: () => Navigator.of(context).push(
+          MaterialPageRoute<void>(
+            builder: (_) => [[.FormPage]](onSaved: [[template "save" .]]),
+          ),
+        ),
+        child: const Icon(Icons.add),
+      )`,
+}
+
+// crudSpecs holds the CRUD templates of every state manager. The list page
+// file declares the route builder, which provides the state holder the way
+// main.dart does for the counter.
+var crudSpecs = map[string]crudSpec{
+	BLoC: {
+		Holder: `
+import 'package:bloc/bloc.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+sealed class [[.Entity]]Event {
+  const [[.Entity]]Event();
+}
+
+final class [[.Plural]]Requested extends [[.Entity]]Event {
+  const [[.Plural]]Requested();
+}
+
+final class [[.Entity]]Saved extends [[.Entity]]Event {
+  const [[.Entity]]Saved(this.[[.Var]]);
+
+  final [[.Entity]] [[.Var]];
+}
+
+final class [[.Entity]]Deleted extends [[.Entity]]Event {
+  const [[.Entity]]Deleted(this.id);
+
+  final String id;
+}
+
+class [[.Holder]] extends Bloc<[[.Entity]]Event, List<[[.Entity]]>> {
+  [[.Holder]]({required [[.Entity]]Repository repository})
+      : _repository = repository,
+        super(const []) {
+    on<[[.Plural]]Requested>((event, emit) async => emit(await _repository.getAll()));
+    on<[[.Entity]]Saved>((event, emit) async {
+      await _repository.save(event.[[.Var]]);
+      emit(await _repository.getAll());
+    });
+    on<[[.Entity]]Deleted>((event, emit) async {
+      await _repository.delete(event.id);
+      emit(await _repository.getAll());
+    });
+    add(const [[.Plural]]Requested());
+  }
+
+  final [[.Entity]]Repository _repository;
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:flutter_bloc/flutter_bloc.dart';
+import '[[.EntityURI]]';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]](saved) => context.read<[[.Holder]]>().add([[.Entity]]Saved(saved))[[end]]
+[[define "delete"]]context.read<[[.Holder]]>().add([[.Entity]]Deleted([[.Var]].id))[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  return BlocProvider(
+    create: (_) => [[.Holder]](repository: sl()),
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: BlocBuilder<[[.Holder]], List<[[.Entity]]>>(
+        builder: (context, [[.PluralVar]]) {
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	Cubit: {
+		Holder: `
+import 'dart:async';
+
+import 'package:bloc/bloc.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] extends Cubit<List<[[.Entity]]>> {
+  [[.Holder]]({required [[.Entity]]Repository repository})
+      : _repository = repository,
+        super(const []) {
+    unawaited(load());
+  }
+
+  final [[.Entity]]Repository _repository;
+
+  Future<void> load() async => emit(await _repository.getAll());
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:flutter_bloc/flutter_bloc.dart';
+import '[[.EntityURI]]';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]]context.read<[[.Holder]]>().save[[end]]
+[[define "delete"]]context.read<[[.Holder]]>().delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  return BlocProvider(
+    create: (_) => [[.Holder]](repository: sl()),
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: BlocBuilder<[[.Holder]], List<[[.Entity]]>>(
+        builder: (context, [[.PluralVar]]) {
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	Provider: {
+		Holder: `
+import 'dart:async';
+
+import 'package:flutter/foundation.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] extends ChangeNotifier {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository {
+    unawaited(load());
+  }
+
+  final [[.Entity]]Repository _repository;
+
+  List<[[.Entity]]> _[[.PluralVar]] = const [];
+
+  List<[[.Entity]]> get [[.PluralVar]] => _[[.PluralVar]];
+
+  Future<void> load() async {
+    _[[.PluralVar]] = await _repository.getAll();
+    notifyListeners();
+  }
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:provider/provider.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]][[.HolderVar]].save[[end]]
+[[define "delete"]][[.HolderVar]].delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  return ChangeNotifierProvider(
+    create: (_) => [[.Holder]](repository: sl()),
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.HolderVar]] = context.watch<[[.Holder]]>();
+    final [[.PluralVar]] = [[.HolderVar]].[[.PluralVar]];
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: [[template "list" .]],
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	Riverpod: {
+		Holder: `
+import 'dart:async';
+
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+import '[[.ContainerURI]]';
+
+final [[.Var]]Provider = NotifierProvider<[[.Holder]], List<[[.Entity]]>>(
+  () => [[.Holder]](repository: sl()),
+);
+
+class [[.Holder]] extends Notifier<List<[[.Entity]]>> {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository;
+
+  final [[.Entity]]Repository _repository;
+
+  @override
+  List<[[.Entity]]> build() {
+    unawaited(load());
+    return const [];
+  }
+
+  Future<void> load() async => state = await _repository.getAll();
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+
+[[define "save"]]ref.read([[.Var]]Provider.notifier).save[[end]]
+[[define "delete"]]ref.read([[.Var]]Provider.notifier).delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends ConsumerWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context, WidgetRef ref) {
+    final [[.PluralVar]] = ref.watch([[.Var]]Provider);
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: [[template "list" .]],
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	GetX: {
+		Holder: `
+import 'dart:async';
+
+import 'package:get/get.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] extends GetxController {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository;
+
+  final [[.Entity]]Repository _repository;
+
+  final [[.PluralVar]] = <[[.Entity]]>[].obs;
+
+  @override
+  void onInit() {
+    super.onInit();
+    unawaited(load());
+  }
+
+  Future<void> load() async => [[.PluralVar]].assignAll(await _repository.getAll());
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:get/get.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]][[.HolderVar]].save[[end]]
+[[define "delete"]][[.HolderVar]].delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  if (!Get.isRegistered<[[.Holder]]>()) {
+    Get.put([[.Holder]](repository: sl()));
+  }
+  return const [[.ListPage]]();
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.HolderVar]] = Get.find<[[.Holder]]>();
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: Obx(() {
+        final [[.PluralVar]] = [[.HolderVar]].[[.PluralVar]];
+        return [[template "list" .]];
+      }),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	MobX: {
+		Holder: `
+import 'dart:async';
+
+import 'package:mobx/mobx.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+part '[[.HolderBase]].g.dart';
+
+class [[.Holder]] = _[[.Holder]] with _$[[.Holder]];
+
+abstract class _[[.Holder]] with Store {
+  _[[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository {
+    unawaited(load());
+  }
+
+  final [[.Entity]]Repository _repository;
+
+  @observable
+  List<[[.Entity]]> [[.PluralVar]] = const [];
+
+  @action
+  Future<void> load() async {
+    [[.PluralVar]] = await _repository.getAll();
+  }
+
+  @action
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  @action
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:flutter_mobx/flutter_mobx.dart';
+import 'package:provider/provider.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]][[.HolderVar]].save[[end]]
+[[define "delete"]][[.HolderVar]].delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  return Provider(
+    create: (_) => [[.Holder]](repository: sl()),
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.HolderVar]] = context.read<[[.Holder]]>();
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: Observer(
+        builder: (_) {
+          final [[.PluralVar]] = [[.HolderVar]].[[.PluralVar]];
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	Redux: {
+		Holder: `
+import 'package:redux/redux.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+sealed class [[.Entity]]Action {
+  const [[.Entity]]Action();
+}
+
+final class [[.Plural]]Requested extends [[.Entity]]Action {
+  const [[.Plural]]Requested();
+}
+
+final class [[.Entity]]SaveRequested extends [[.Entity]]Action {
+  const [[.Entity]]SaveRequested(this.[[.Var]]);
+
+  final [[.Entity]] [[.Var]];
+}
+
+final class [[.Entity]]DeleteRequested extends [[.Entity]]Action {
+  const [[.Entity]]DeleteRequested(this.id);
+
+  final String id;
+}
+
+final class [[.Plural]]Loaded extends [[.Entity]]Action {
+  const [[.Plural]]Loaded(this.[[.PluralVar]]);
+
+  final List<[[.Entity]]> [[.PluralVar]];
+}
+
+List<[[.Entity]]> [[.Var]]Reducer(List<[[.Entity]]> state, dynamic action) {
+  if (action is [[.Plural]]Loaded) {
+    return action.[[.PluralVar]];
+  }
+  return state;
+}
+
+Middleware<List<[[.Entity]]>> [[.Var]]Middleware({required [[.Entity]]Repository repository}) {
+  return (store, action, next) async {
+    next(action);
+    if (action is [[.Entity]]SaveRequested) {
+      await repository.save(action.[[.Var]]);
+    } else if (action is [[.Entity]]DeleteRequested) {
+      await repository.delete(action.id);
+    } else if (action is! [[.Plural]]Requested) {
+      return;
+    }
+    store.dispatch([[.Plural]]Loaded(await repository.getAll()));
+  };
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:flutter_redux/flutter_redux.dart';
+import 'package:redux/redux.dart';
+import '[[.EntityURI]]';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]](saved) => StoreProvider.of<List<[[.Entity]]>>(context).dispatch([[.Entity]]SaveRequested(saved))[[end]]
+[[define "delete"]]StoreProvider.of<List<[[.Entity]]>>(context).dispatch([[.Entity]]DeleteRequested([[.Var]].id))[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  final store = Store<List<[[.Entity]]>>(
+    [[.Var]]Reducer,
+    initialState: const [],
+    middleware: [ [[.Var]]Middleware(repository: sl())],
+  )..dispatch(const [[.Plural]]Requested());
+  return StoreProvider<List<[[.Entity]]>>(
+    store: store,
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: StoreConnector<List<[[.Entity]]>, List<[[.Entity]]>>(
+        converter: (store) => store.state,
+        builder: (context, [[.PluralVar]]) {
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	ScopedModel: {
+		Holder: `
+import 'dart:async';
+
+import 'package:scoped_model/scoped_model.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] extends Model {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository {
+    unawaited(load());
+  }
+
+  final [[.Entity]]Repository _repository;
+
+  List<[[.Entity]]> _[[.PluralVar]] = const [];
+
+  List<[[.Entity]]> get [[.PluralVar]] => _[[.PluralVar]];
+
+  Future<void> load() async {
+    _[[.PluralVar]] = await _repository.getAll();
+    notifyListeners();
+  }
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:scoped_model/scoped_model.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]]ScopedModel.of<[[.Holder]]>(context).save[[end]]
+[[define "delete"]]ScopedModel.of<[[.Holder]]>(context).delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  return ScopedModel<[[.Holder]]>(
+    model: [[.Holder]](repository: sl()),
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: ScopedModelDescendant<[[.Holder]]>(
+        builder: (context, child, model) {
+          final [[.PluralVar]] = model.[[.PluralVar]];
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	StatesRebuilder: {
+		Holder: `
+import 'package:states_rebuilder/states_rebuilder.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+import '[[.ContainerURI]]';
+
+final [[.Var]]RM = RM.inject(() => [[.Holder]](repository: sl()));
+
+class [[.Holder]] {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository;
+
+  final [[.Entity]]Repository _repository;
+
+  List<[[.Entity]]> [[.PluralVar]] = const [];
+
+  Future<void> load() async => [[.PluralVar]] = await _repository.getAll();
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:states_rebuilder/states_rebuilder.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+
+[[define "save"]](saved) => [[.Var]]RM.setState((s) => s.save(saved))[[end]]
+[[define "delete"]][[.Var]]RM.setState((s) => s.delete([[.Var]].id))[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: OnBuilder(
+        listenTo: [[.Var]]RM,
+        sideEffects: SideEffects(
+          initState: () => [[.Var]]RM.setState((s) => s.load()),
+        ),
+        builder: () {
+          final [[.PluralVar]] = [[.Var]]RM.state.[[.PluralVar]];
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	MvcPattern: {
+		Holder: `
+import 'dart:async';
+
+import 'package:mvc_pattern/mvc_pattern.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] extends ControllerMVC {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository {
+    unawaited(load());
+  }
+
+  final [[.Entity]]Repository _repository;
+
+  List<[[.Entity]]> _[[.PluralVar]] = const [];
+
+  List<[[.Entity]]> get [[.PluralVar]] => _[[.PluralVar]];
+
+  Future<void> load() async {
+    final [[.PluralVar]] = await _repository.getAll();
+    setState(() => _[[.PluralVar]] = [[.PluralVar]]);
+  }
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:mvc_pattern/mvc_pattern.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]]con.save[[end]]
+[[define "delete"]]con.delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends StatefulWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  State<[[.ListPage]]> createState() => _[[.ListPage]]State();
+}
+
+class _[[.ListPage]]State extends StateMVC<[[.ListPage]]> {
+  _[[.ListPage]]State() : super([[.Holder]](repository: sl())) {
+    con = controller! as [[.Holder]];
+  }
+
+  late final [[.Holder]] con;
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.PluralVar]] = con.[[.PluralVar]];
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: [[template "list" .]],
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	Stacked: {
+		Holder: `
+import 'package:stacked/stacked.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] extends BaseViewModel {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository;
+
+  final [[.Entity]]Repository _repository;
+
+  List<[[.Entity]]> _[[.PluralVar]] = const [];
+
+  List<[[.Entity]]> get [[.PluralVar]] => _[[.PluralVar]];
+
+  Future<void> load() async {
+    _[[.PluralVar]] = await runBusyFuture(_repository.getAll());
+    rebuildUi();
+  }
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'dart:async';
+
+import 'package:flutter/material.dart';
+import 'package:stacked/stacked.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]]viewModel.save[[end]]
+[[define "delete"]]viewModel.delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends StackedView<[[.Holder]]> {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget builder(BuildContext context, [[.Holder]] viewModel, Widget? child) {
+    final [[.PluralVar]] = viewModel.[[.PluralVar]];
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: [[template "list" .]],
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+
+  @override
+  [[.Holder]] viewModelBuilder(BuildContext context) => [[.Holder]](repository: sl());
+
+  @override
+  void onViewModelReady([[.Holder]] viewModel) => unawaited(viewModel.load());
+}
+`,
+	},
+
+	FlutterModular: {
+		Holder: `
+import 'dart:async';
+
+import 'package:flutter/foundation.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] extends ChangeNotifier {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository {
+    unawaited(load());
+  }
+
+  final [[.Entity]]Repository _repository;
+
+  List<[[.Entity]]> _[[.PluralVar]] = const [];
+
+  List<[[.Entity]]> get [[.PluralVar]] => _[[.PluralVar]];
+
+  Future<void> load() async {
+    _[[.PluralVar]] = await _repository.getAll();
+    notifyListeners();
+  }
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]][[.HolderVar]].save[[end]]
+[[define "delete"]][[.HolderVar]].delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends StatefulWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  State<[[.ListPage]]> createState() => _[[.ListPage]]State();
+}
+
+class _[[.ListPage]]State extends State<[[.ListPage]]> {
+  final [[.HolderVar]] = [[.Holder]](repository: sl());
+
+  @override
+  void dispose() {
+    [[.HolderVar]].dispose();
+    super.dispose();
+  }
+
+  @override
+  Widget build(BuildContext context) {
+    return ListenableBuilder(
+      listenable: [[.HolderVar]],
+      builder: (context, _) {
+        final [[.PluralVar]] = [[.HolderVar]].[[.PluralVar]];
+        return Scaffold(
+          appBar: AppBar(
+            title: const Text('[[.PluralLabel]]'),
+          ),
+          body: [[template "list" .]],
+          floatingActionButton: [[template "add" .]],
+        );
+      },
+    );
+  }
+}
+`,
+	},
+
+	RiverpodGenerator: {
+		Holder: `
+import 'dart:async';
+
+import 'package:riverpod_annotation/riverpod_annotation.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+import '[[.ContainerURI]]';
+
+part '[[.HolderBase]].g.dart';
+
+@Riverpod(keepAlive: true)
+[[.Entity]]Repository [[.Var]]Repository(Ref ref) => sl();
+
+@riverpod
+class [[.Holder]] extends _$[[.Holder]] {
+  [[.Entity]]Repository get _repository => ref.read([[.Var]]RepositoryProvider);
+
+  @override
+  List<[[.Entity]]> build() {
+    unawaited(load());
+    return const [];
+  }
+
+  Future<void> load() async => state = await _repository.getAll();
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+
+[[define "save"]]ref.read([[.HolderVar]]Provider.notifier).save[[end]]
+[[define "delete"]]ref.read([[.HolderVar]]Provider.notifier).delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends ConsumerWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context, WidgetRef ref) {
+    final [[.PluralVar]] = ref.watch([[.HolderVar]]Provider);
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: [[template "list" .]],
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	HooksRiverpod: {
+		Holder: `
+import 'package:hooks_riverpod/hooks_riverpod.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+import '[[.ContainerURI]]';
+
+final [[.Var]]Provider = NotifierProvider<[[.Holder]], List<[[.Entity]]>>(
+  () => [[.Holder]](repository: sl()),
+);
+
+class [[.Holder]] extends Notifier<List<[[.Entity]]>> {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository;
+
+  final [[.Entity]]Repository _repository;
+
+  @override
+  List<[[.Entity]]> build() => const [];
+
+  Future<void> load() async => state = await _repository.getAll();
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'dart:async';
+
+import 'package:flutter/material.dart';
+import 'package:flutter_hooks/flutter_hooks.dart';
+import 'package:hooks_riverpod/hooks_riverpod.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+
+[[define "save"]]ref.read([[.Var]]Provider.notifier).save[[end]]
+[[define "delete"]]ref.read([[.Var]]Provider.notifier).delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends HookConsumerWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context, WidgetRef ref) {
+    final [[.PluralVar]] = ref.watch([[.Var]]Provider);
+    useEffect(() {
+      unawaited(ref.read([[.Var]]Provider.notifier).load());
+      return null;
+    }, const []);
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: [[template "list" .]],
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	Signals: {
+		Holder: `
+import 'dart:async';
+
+import 'package:signals/signals.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+class [[.Holder]] {
+  [[.Holder]]({required [[.Entity]]Repository repository}) : _repository = repository {
+    unawaited(load());
+  }
+
+  final [[.Entity]]Repository _repository;
+
+  final _[[.PluralVar]] = signal<List<[[.Entity]]>>(const []);
+
+  ReadonlySignal<List<[[.Entity]]>> get [[.PluralVar]] => _[[.PluralVar]];
+
+  Future<void> load() async => _[[.PluralVar]].value = await _repository.getAll();
+
+  Future<void> save([[.Entity]] [[.Var]]) async {
+    await _repository.save([[.Var]]);
+    await load();
+  }
+
+  Future<void> delete(String id) async {
+    await _repository.delete(id);
+    await load();
+  }
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:signals/signals_flutter.dart';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]][[.HolderVar]].save[[end]]
+[[define "delete"]][[.HolderVar]].delete([[.Var]].id)[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) => const [[.ListPage]]();
+
+class [[.ListPage]] extends StatefulWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  State<[[.ListPage]]> createState() => _[[.ListPage]]State();
+}
+
+class _[[.ListPage]]State extends State<[[.ListPage]]> {
+  final [[.HolderVar]] = [[.Holder]](repository: sl());
+
+  @override
+  Widget build(BuildContext context) {
+    return Watch((context) {
+      final [[.PluralVar]] = [[.HolderVar]].[[.PluralVar]].value;
+      return Scaffold(
+        appBar: AppBar(
+          title: const Text('[[.PluralLabel]]'),
+        ),
+        body: [[template "list" .]],
+        floatingActionButton: [[template "add" .]],
+      );
+    });
+  }
+}
+`,
+	},
+
+	ReduxThunk: {
+		Holder: `
+import 'package:redux/redux.dart';
+import 'package:redux_thunk/redux_thunk.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+
+final class [[.Plural]]Loaded {
+  const [[.Plural]]Loaded(this.[[.PluralVar]]);
+
+  final List<[[.Entity]]> [[.PluralVar]];
+}
+
+final [[.Var]]Reducer = TypedReducer<List<[[.Entity]]>, [[.Plural]]Loaded>(
+  (_, action) => action.[[.PluralVar]],
+).call;
+
+ThunkAction<List<[[.Entity]]>> load[[.Plural]]([[.Entity]]Repository repository) {
+  return (store) async {
+    store.dispatch([[.Plural]]Loaded(await repository.getAll()));
+  };
+}
+
+ThunkAction<List<[[.Entity]]>> save[[.Entity]]([[.Entity]]Repository repository, [[.Entity]] [[.Var]]) {
+  return (store) async {
+    await repository.save([[.Var]]);
+    await store.dispatch(load[[.Plural]](repository));
+  };
+}
+
+ThunkAction<List<[[.Entity]]>> delete[[.Entity]]([[.Entity]]Repository repository, String id) {
+  return (store) async {
+    await repository.delete(id);
+    await store.dispatch(load[[.Plural]](repository));
+  };
+}
+`,
+		List: `
+import 'package:flutter/material.dart';
+import 'package:flutter_redux/flutter_redux.dart';
+import 'package:redux/redux.dart';
+import 'package:redux_thunk/redux_thunk.dart';
+import '[[.EntityURI]]';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+import '[[.ContainerURI]]';
+
+[[define "save"]](saved) => StoreProvider.of<List<[[.Entity]]>>(context).dispatch(save[[.Entity]](sl(), saved))[[end]]
+[[define "delete"]]StoreProvider.of<List<[[.Entity]]>>(context).dispatch(delete[[.Entity]](sl(), [[.Var]].id))[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  final store = Store<List<[[.Entity]]>>(
+    [[.Var]]Reducer,
+    initialState: const [],
+    middleware: [thunkMiddleware],
+  )..dispatch(load[[.Plural]](sl()));
+  return StoreProvider<List<[[.Entity]]>>(
+    store: store,
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: StoreConnector<List<[[.Entity]]>, List<[[.Entity]]>>(
+        converter: (store) => store.state,
+        builder: (context, [[.PluralVar]]) {
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+
+	AsyncRedux: {
+		Holder: `
+import 'package:async_redux/async_redux.dart';
+import '[[.EntityURI]]';
+import '[[.RepositoryURI]]';
+import '[[.ContainerURI]]';
+
+abstract class [[.Entity]]Action extends ReduxAction<List<[[.Entity]]>> {
+  [[.Entity]]Repository get repository => sl();
+}
+
+class Load[[.Plural]]Action extends [[.Entity]]Action {
+  @override
+  Future<List<[[.Entity]]>?> reduce() => repository.getAll();
+}
+
+class Save[[.Entity]]Action extends [[.Entity]]Action {
+  Save[[.Entity]]Action(this.[[.Var]]);
+
+  final [[.Entity]] [[.Var]];
+
+  @override
+  Future<List<[[.Entity]]>?> reduce() async {
+    await repository.save([[.Var]]);
+    return repository.getAll();
+  }
+}
+
+class Delete[[.Entity]]Action extends [[.Entity]]Action {
+  Delete[[.Entity]]Action(this.id);
+
+  final String id;
+
+  @override
+  Future<List<[[.Entity]]>?> reduce() async {
+    await repository.delete(id);
+    return repository.getAll();
+  }
+}
+`,
+		List: `
+import 'package:async_redux/async_redux.dart';
+import 'package:flutter/material.dart';
+import '[[.EntityURI]]';
+import '[[.HolderURI]]';
+import '[[.DetailURI]]';
+import '[[.FormURI]]';
+
+[[define "save"]](saved) => StoreProvider.dispatch<List<[[.Entity]]>>(context, Save[[.Entity]]Action(saved))[[end]]
+[[define "delete"]]StoreProvider.dispatch<List<[[.Entity]]>>(context, Delete[[.Entity]]Action([[.Var]].id))[[end]]
+
+Widget [[.Var]]ListRoute(BuildContext context) {
+  final store = Store<List<[[.Entity]]>>(initialState: const [])..dispatch(Load[[.Plural]]Action());
+  return StoreProvider<List<[[.Entity]]>>(
+    store: store,
+    child: const [[.ListPage]](),
+  );
+}
+
+class [[.ListPage]] extends StatelessWidget {
+  const [[.ListPage]]({super.key});
+
+  static const routeName = '[[.RouteName]]';
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.PluralLabel]]'),
+      ),
+      body: StoreConnector<List<[[.Entity]]>, List<[[.Entity]]>>(
+        converter: (store) => store.state,
+        builder: (context, [[.PluralVar]]) {
+          return [[template "list" .]];
+        },
+      ),
+      floatingActionButton: [[template "add" .]],
+    );
+  }
+}
+`,
+	},
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+func diffCommand(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	project := flags.String("project", ".", "path of the project, the folder holding "+manifestFile)
+	recorded := flags.Bool("recorded", false, "compare with the files recorded when the project was generated instead of the current templates, without running flutter")
+	names := flags.Bool("names", false, "only list the files that differ")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch diff [flags]")
+		flags.PrintDefaults()
+	}
+	if positional := parseInterspersed(flags, args); len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+
+	rootPath := *project
+	manifest, err := readManifest(rootPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	drift, err := diffProject(rootPath, manifest, *recorded)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	modified, deleted, missing, extra := drift.Modified, drift.Deleted, drift.Missing, drift.Extra
+
+	if len(modified)+len(deleted)+len(missing)+len(extra) == 0 {
+		fmt.Printf("%s matches the %s scaffold.\n", manifest.ProjectName, manifest.Architecture)
+		return
+	}
+	list := func(status string, files []string) {
+		for _, file := range files {
+			fmt.Printf("%-9s %s\n", status, file)
+		}
+	}
+	list("modified", modified)
+	list("deleted", deleted)
+	list("missing", missing)
+	list("extra", extra)
+	if !*names {
+		fmt.Println()
+		fmt.Print(drift.Diffs)
+	}
+
+	// Report the drift through the exit status, for scripts and CI
+	os.Exit(1)
+}
+
+// projectDrift lists how the files of a project differ from its scaffold.
+type projectDrift struct {
+	Modified, Deleted, Missing, Extra []string
+	// Diffs holds the unified diffs of the files
+	Diffs string
+}
+
+// diffProject compares the project at rootPath with the scaffold of its
+// manifest, the one recorded at generation or, unless recorded is set, the
+// one the current templates generate.
+func diffProject(rootPath string, manifest projectManifest, recorded bool) (projectDrift, error) {
+	// expected returns the content the scaffold has for a file
+	expected := func(file string) (string, bool) {
+		content, ok := manifest.Base[file]
+		return content, ok
+	}
+	expectedFiles := manifest.Files
+	if !recorded {
+		// Keep the output of the generation off the diff
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		tmp, generated, err := generateAgain(manifest)
+		os.Stdout = stdout
+		if tmp != "" {
+			defer os.RemoveAll(tmp)
+		}
+		if err != nil {
+			return projectDrift{}, err
+		}
+
+		generatedRoot := filepath.Join(tmp, manifest.ProjectName)
+		expectedFiles = generated.Files
+		// flutter pub add writes the latest versions, which are not a change
+		// of the templates
+		pubspecs := make(map[string]string)
+		for file := range expectedFiles {
+			if path.Base(file) != "pubspec.yaml" {
+				continue
+			}
+			theirs, err := os.ReadFile(filepath.Join(generatedRoot, filepath.FromSlash(file)))
+			if err != nil {
+				continue
+			}
+			ours, err := os.ReadFile(filepath.Join(rootPath, filepath.FromSlash(file)))
+			if err != nil {
+				continue
+			}
+			pubspecs[file] = keepVersionConstraints(string(theirs), string(ours))
+			expectedFiles[file] = hashContent([]byte(pubspecs[file]))
+		}
+		expected = func(file string) (string, bool) {
+			if content, ok := pubspecs[file]; ok {
+				return content, true
+			}
+			content, err := os.ReadFile(filepath.Join(generatedRoot, filepath.FromSlash(file)))
+			return string(content), err == nil && utf8.Valid(content)
+		}
+	}
+
+	var paths []string
+	for file := range expectedFiles {
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+
+	var modified, deleted, missing []string
+	var diffs strings.Builder
+	for _, file := range paths {
+		current, err := os.ReadFile(filepath.Join(rootPath, filepath.FromSlash(file)))
+		exists := err == nil
+		if exists && hashContent(current) == expectedFiles[file] {
+			continue
+		}
+
+		switch {
+		case !exists:
+			if _, generated := manifest.Files[file]; generated {
+				deleted = append(deleted, file)
+			} else {
+				missing = append(missing, file)
+			}
+		default:
+			modified = append(modified, file)
+		}
+
+		content, text := expected(file)
+		if !text {
+			fmt.Fprintf(&diffs, "Binary files a/%s and b/%s differ\n", file, file)
+			continue
+		}
+		toName := "b/" + file
+		if !exists {
+			toName = "/dev/null"
+		}
+		diffs.WriteString(unifiedDiff("a/"+file, toName, content, string(current)))
+	}
+
+	// Files of the project the templates do not generate anymore
+	var extra []string
+	if !recorded {
+		for file := range manifest.Files {
+			if _, ok := expectedFiles[file]; ok {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(rootPath, filepath.FromSlash(file))); err == nil {
+				extra = append(extra, file)
+			}
+		}
+		sort.Strings(extra)
+	}
+
+	return projectDrift{modified, deleted, missing, extra, diffs.String()}, nil
+}
+
+// unifiedDiff returns the changes from a to b in the unified format, or
+// nothing when they are equal.
+func unifiedDiff(fromName, toName, a, b string) string {
+	from, to := splitLines(a), splitLines(b)
+	matches := matchLines(from, to)
+
+	// Edit script: ' ' keeps a line, '-' removes one of from, '+' adds one of
+	// to
+	type edit struct {
+		Op   byte
+		Line string
+		// From and To are the indexes of the next lines of both sides
+		From, To int
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && matches[i] == j:
+			edits = append(edits, edit{' ', from[i], i, j})
+			i++
+			j++
+		case i < len(from) && matches[i] == -1:
+			edits = append(edits, edit{'-', from[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', to[j], i, j})
+			j++
+		}
+	}
+
+	var out strings.Builder
+	for start := 0; start < len(edits); {
+		// Find the next change and extend the hunk while at most 2*diffContext
+		// unchanged lines separate the changes
+		first := start
+		for first < len(edits) && edits[first].Op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for k := first; k < len(edits) && k <= last+2*diffContext+1; k++ {
+			if edits[k].Op != ' ' {
+				last = k
+			}
+		}
+		lo := first - diffContext
+		if lo < start {
+			lo = start
+		}
+		hi := last + diffContext + 1
+		if hi > len(edits) {
+			hi = len(edits)
+		}
+
+		fromCount, toCount := 0, 0
+		for _, e := range edits[lo:hi] {
+			if e.Op != '+' {
+				fromCount++
+			}
+			if e.Op != '-' {
+				toCount++
+			}
+		}
+		fromStart, toStart := edits[lo].From, edits[lo].To
+		if fromCount > 0 {
+			fromStart++
+		}
+		if toCount > 0 {
+			toStart++
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(fromStart, fromCount), hunkRange(toStart, toCount))
+		for _, e := range edits[lo:hi] {
+			out.WriteByte(e.Op)
+			out.WriteString(e.Line)
+			if !strings.HasSuffix(e.Line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		start = hi
+	}
+	return out.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
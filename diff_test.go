@@ -0,0 +1,220 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// numberLines returns the lines 1 to n, replacing the ones of changes.
+func numberLines(n int, changes map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		line, ok := changes[i]
+		if !ok {
+			line = strconv.Itoa(i)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "context around a change",
+			a:    numberLines(10, nil),
+			b:    numberLines(10, map[int]string{5: "five"}),
+			want: `--- a/f
++++ b/f
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name: "close changes share a hunk",
+			a:    numberLines(20, nil),
+			b:    numberLines(20, map[int]string{3: "three", 10: "ten"}),
+			want: `--- a/f
++++ b/f
+@@ -1,13 +1,13 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+ 7
+ 8
+ 9
+-10
++ten
+ 11
+ 12
+ 13
+`,
+		},
+		{
+			name: "distant changes get their own hunks",
+			a:    numberLines(20, nil),
+			b:    numberLines(20, map[int]string{3: "three", 11: "eleven"}),
+			want: `--- a/f
++++ b/f
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -8,7 +8,7 @@
+ 8
+ 9
+ 10
+-11
++eleven
+ 12
+ 13
+ 14
+`,
+		},
+		{
+			name: "added to an empty file",
+			a:    "",
+			b:    "a\nb\n",
+			want: `--- a/f
++++ b/f
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name: "emptied",
+			a:    "a\nb\n",
+			b:    "",
+			want: `--- a/f
++++ b/f
+@@ -1,2 +0,0 @@
+-a
+-b
+`,
+		},
+		{
+			name: "single lines",
+			a:    "a\n",
+			b:    "b\n",
+			want: `--- a/f
++++ b/f
+@@ -1 +1 @@
+-a
++b
+`,
+		},
+		{
+			name: "no newline at end of file",
+			a:    "a\nb",
+			b:    "a\nc\n",
+			want: `--- a/f
++++ b/f
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+`,
+		},
+		{
+			name: "start and end",
+			a:    "x\n1\n2\n",
+			b:    "1\n2\ny\n",
+			want: `--- a/f
++++ b/f
+@@ -1,3 +1,3 @@
+-x
+ 1
+ 2
++y
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a/f", "b/f", tt.a, tt.b); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakePubAddScript extends the fake flutter command: pub add lists the
+// packages in pubspec.yaml with the version in $FAKE_PUB_VERSION.
+const fakePubAddScript = `if [ "$1" = pub ] && [ "$2" = add ]; then
+  shift 2
+  for a in "$@"; do echo "  ${a#dev:}: ^$FAKE_PUB_VERSION" >> pubspec.yaml; done
+fi
+`
+
+func TestDiffProjectIgnoresNewerConstraints(t *testing.T) {
+	log := useFakeFlutter(t)
+	script := strings.Replace(fakeFlutterScript, "exit 0\n", fakePubAddScript+"exit 0\n", 1)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(log), "flutter"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FAKE_PUB_VERSION", "1.0.0")
+
+	opts := defaultProjectOptions()
+	opts.ProjectName, opts.Path, opts.GitInit = "demo", t.TempDir(), false
+	opts.Pattern, opts.StateManager = patterns[0], stateManagers[0]
+	initializeProject(opts)
+	rootPath := filepath.Join(opts.Path, "demo")
+	manifest, err := readManifest(rootPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubspec := readTestFile(t, filepath.Join(rootPath, "pubspec.yaml"))
+	if !strings.Contains(pubspec, "^1.0.0") {
+		t.Fatalf("pub add did not write the constraints:\n%s", pubspec)
+	}
+
+	// Newer releases on pub only change the constraints
+	t.Setenv("FAKE_PUB_VERSION", "2.0.0")
+	drift, err := diffProject(rootPath, manifest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift.Modified)+len(drift.Deleted)+len(drift.Missing)+len(drift.Extra) != 0 {
+		t.Errorf("the project should match its scaffold, got %+v", drift)
+	}
+
+	// Other changes of pubspec.yaml are still reported
+	writeTestFiles(t, rootPath, map[string]string{"pubspec.yaml": pubspec + "# changed\n"})
+	drift, err = diffProject(rootPath, manifest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(drift.Modified, ",") != "pubspec.yaml" || !strings.Contains(drift.Diffs, "+# changed") {
+		t.Errorf("pubspec.yaml should be modified, got %+v", drift)
+	}
+}
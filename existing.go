@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Policies for a target directory that already exists
+const (
+	AbortExisting     string = "Abort"
+	OverwriteExisting string = "Overwrite, keeping a backup of the whole folder"
+	MergeExisting     string = "Only add the new files"
+	PromptExisting    string = "Ask for each existing file"
+)
+
+// List of existing directory policies
+var existingPolicies = []string{
+	AbortExisting,
+	OverwriteExisting,
+	MergeExisting,
+	PromptExisting,
+}
+
+// existingPolicyFlags maps the values of the --existing flag to the policies.
+var existingPolicyFlags = map[string]string{
+	"abort":     AbortExisting,
+	"overwrite": OverwriteExisting,
+	"merge":     MergeExisting,
+	"prompt":    PromptExisting,
+}
+
+// mergedInPlace are the files the generation edits without replacing their
+// content, so the merge and prompt policies keep the edits.
+var mergedInPlace = map[string]bool{
+	"pubspec.yaml": true,
+	".gitignore":   true,
+}
+
+// existingTarget backs up a target directory before the project is
+// generated into it, and applies the policy to the files it had once the
+// generation is done.
+type existingTarget struct {
+	Path       string
+	BackupPath string
+	Policy     string
+	// Files are the slash-separated paths of the files the directory had
+	Files []string
+	// Replaced are the files the user chose to take the generated version of
+	Replaced map[string]bool
+}
+
+// targetExists reports whether the directory the project is generated in
+// already exists.
+func targetExists(opts projectOptions) bool {
+	_, err := os.Stat(filepath.Join(opts.Path, opts.ProjectName))
+	return err == nil
+}
+
+// backupExisting copies the files of the target directory to a timestamped
+// folder next to it.
+func backupExisting(targetPath, policy string) (*existingTarget, error) {
+	target := &existingTarget{
+		Path:       targetPath,
+		BackupPath: targetPath + ".backup-" + time.Now().Format("20060102-150405"),
+		Policy:     policy,
+	}
+	err := filepath.WalkDir(targetPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if manifestIgnoredDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(targetPath, filePath)
+		if err != nil {
+			return err
+		}
+		if err := copyFile(filePath, filepath.Join(target.BackupPath, rel)); err != nil {
+			return err
+		}
+		target.Files = append(target.Files, filepath.ToSlash(rel))
+		return nil
+	})
+	return target, err
+}
+
+// prepare settles the policy before anything is generated: the overwrite
+// policy empties the directory, so that no file of the previous tree is left
+// behind, and the prompt policy asks about the files the tool generates.
+func (t *existingTarget) prepare(opts projectOptions) error {
+	switch t.Policy {
+	case OverwriteExisting:
+		for _, file := range t.Files {
+			if mergedInPlace[filepath.Base(file)] {
+				continue
+			}
+			if err := os.Remove(filepath.Join(t.Path, filepath.FromSlash(file))); err != nil {
+				return fmt.Errorf("%w, the previous files are in %s", err, t.BackupPath)
+			}
+			removeEmptyDirs(t.Path, filepath.Dir(filepath.Join(t.Path, filepath.FromSlash(file))))
+		}
+	case PromptExisting:
+		planned, err := plannedFiles(opts)
+		if err != nil {
+			os.RemoveAll(t.BackupPath)
+			return err
+		}
+		existing := make(map[string]bool)
+		for _, file := range t.Files {
+			existing[file] = true
+		}
+		t.Replaced = make(map[string]bool)
+		for _, file := range planned {
+			if !existing[file] || mergedInPlace[filepath.Base(file)] {
+				continue
+			}
+			replace := false
+			promptConfirm := &survey.Confirm{
+				Message: fmt.Sprintf("%s already exists, replace it with the generated version?", file),
+			}
+			if err := survey.AskOne(promptConfirm, &replace); err != nil {
+				// Nothing was changed yet
+				os.RemoveAll(t.BackupPath)
+				return err
+			}
+			t.Replaced[file] = replace
+		}
+	}
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents up to root while they are
+// empty.
+func removeEmptyDirs(root, dir string) {
+	for dir != root && strings.HasPrefix(dir, root) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// resolve applies the policy to the files the generation changed: the merge
+// policy restores them all, the prompt policy the ones the user did not
+// replace. It returns the restored files and removes the backup when no file
+// it holds was changed or removed.
+func (t *existingTarget) resolve() (restored []string) {
+	var changed []string
+	for _, file := range t.Files {
+		current, err := os.ReadFile(filepath.Join(t.Path, filepath.FromSlash(file)))
+		backup, _ := os.ReadFile(filepath.Join(t.BackupPath, filepath.FromSlash(file)))
+		if err != nil || !bytes.Equal(current, backup) {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+
+	var overwritten, removed []string
+	for _, file := range changed {
+		restore := false
+		switch t.Policy {
+		case MergeExisting:
+			restore = !mergedInPlace[filepath.Base(file)]
+		case PromptExisting:
+			// The files the user was not asked about are not generated by
+			// the tool, they keep their content like with the merge policy
+			restore = !mergedInPlace[filepath.Base(file)] && !t.Replaced[file]
+		}
+
+		if !restore {
+			if _, err := os.Stat(filepath.Join(t.Path, filepath.FromSlash(file))); err != nil {
+				removed = append(removed, file)
+			} else {
+				overwritten = append(overwritten, file)
+			}
+			continue
+		}
+		dest := filepath.Join(t.Path, filepath.FromSlash(file))
+		if err := copyFile(filepath.Join(t.BackupPath, filepath.FromSlash(file)), dest); err != nil {
+			fmt.Println("Error restoring file:", err)
+			continue
+		}
+		restored = append(restored, file)
+	}
+
+	if len(overwritten) == 0 && len(removed) == 0 {
+		os.RemoveAll(t.BackupPath)
+		return restored
+	}
+	if len(overwritten) > 0 {
+		fmt.Printf("%d existing files were changed, the previous versions are in %s\n", len(overwritten), t.BackupPath)
+	}
+	if len(removed) > 0 {
+		fmt.Printf("%d existing files are not part of the new project and were removed, they are kept in %s:\n", len(removed), t.BackupPath)
+		for _, file := range removed {
+			fmt.Println("  " + file)
+		}
+	}
+	return restored
+}
+
+func copyFile(src, dest string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dest, content, info.Mode().Perm())
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOverwriteExistingRemovesStaleFiles(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "demo")
+	writeTestFiles(t, target, map[string]string{
+		"pubspec.yaml":          "name: demo\n",
+		"lib/main.dart":         "void main() {}\n",
+		"lib/old/old_page.dart": "class OldPage {}\n",
+		".git/HEAD":             "ref: refs/heads/main\n",
+	})
+
+	existing, err := backupExisting(target, OverwriteExisting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := existing.prepare(projectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"lib/main.dart", "lib/old/old_page.dart", "lib/old"} {
+		if _, err := os.Stat(filepath.Join(target, file)); err == nil {
+			t.Errorf("%s should be removed before the generation", file)
+		}
+	}
+	for _, file := range []string{"pubspec.yaml", ".git/HEAD"} {
+		if _, err := os.Stat(filepath.Join(target, file)); err != nil {
+			t.Errorf("%s should be kept: %v", file, err)
+		}
+	}
+
+	// The generation writes its own files
+	writeTestFiles(t, target, map[string]string{"lib/main.dart": "void main() => runApp(const MyApp());\n"})
+	if restored := existing.resolve(); len(restored) != 0 {
+		t.Errorf("restored %v, the overwrite policy restores nothing", restored)
+	}
+	if _, err := os.Stat(filepath.Join(target, "lib", "old", "old_page.dart")); err == nil {
+		t.Error("old_page.dart is back in the project")
+	}
+	if got := readTestFile(t, filepath.Join(existing.BackupPath, "lib", "old", "old_page.dart")); got != "class OldPage {}\n" {
+		t.Errorf("the backup lost old_page.dart: %q", got)
+	}
+}
+
+func TestMergeExistingKeepsFiles(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "demo")
+	writeTestFiles(t, target, map[string]string{
+		"lib/main.dart":         "void main() {}\n",
+		"lib/old/old_page.dart": "class OldPage {}\n",
+	})
+
+	existing, err := backupExisting(target, MergeExisting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := existing.prepare(projectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFiles(t, target, map[string]string{
+		"lib/main.dart": "void main() => runApp(const MyApp());\n",
+		"lib/app.dart":  "class MyApp {}\n",
+	})
+	if restored := existing.resolve(); len(restored) != 1 || restored[0] != "lib/main.dart" {
+		t.Errorf("restored %v, want [lib/main.dart]", restored)
+	}
+	if got := readTestFile(t, filepath.Join(target, "lib", "main.dart")); got != "void main() {}\n" {
+		t.Errorf("main.dart = %q, want the existing version", got)
+	}
+	for _, file := range []string{"lib/old/old_page.dart", "lib/app.dart"} {
+		if _, err := os.Stat(filepath.Join(target, file)); err != nil {
+			t.Errorf("%s should be in the project: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(existing.BackupPath); err == nil {
+		t.Error("the backup should be removed when no existing file changed")
+	}
+}
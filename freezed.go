@@ -0,0 +1,92 @@
+package main
+
+// Packages added when models are generated with freezed and json_serializable
+var (
+	freezedPackages    = []string{"freezed_annotation", "json_annotation"}
+	freezedDevPackages = []string{"build_runner", "freezed", "json_serializable"}
+)
+
+// freezedStateTemplate is the immutable state of the state holders keeping
+// their whole state in a single value.
+const freezedStateTemplate = `
+import 'package:freezed_annotation/freezed_annotation.dart';
+
+part 'counter_state.freezed.dart';
+part 'counter_state.g.dart';
+
+@freezed
+abstract class CounterState with _$CounterState {
+  const factory CounterState({@Default(0) int count}) = _CounterState;
+
+  factory CounterState.fromJson(Map<String, dynamic> json) =>
+      _$CounterStateFromJson(json);
+}
+`
+
+// StateType is the type of the state kept by the state holder.
+func (d *sampleData) StateType() string {
+	if d.StateClass {
+		return "CounterState"
+	}
+	return "int"
+}
+
+// InitialState is the state the state holder starts with.
+func (d *sampleData) InitialState() string {
+	if d.StateClass {
+		return "const CounterState()"
+	}
+	return "0"
+}
+
+// StateImport returns the import directive of the state class, if any.
+func (d *sampleData) StateImport() string {
+	if !d.StateClass {
+		return ""
+	}
+	return "import '" + d.StateURI + "';\n"
+}
+
+// Count returns the count held by the state expression.
+func (d *sampleData) Count(state string) string {
+	if d.StateClass {
+		return state + ".count"
+	}
+	return state
+}
+
+// Emit returns the state holding the count expression, derived from the
+// current state.
+func (d *sampleData) Emit(count string) string {
+	if d.StateClass {
+		return "state.copyWith(count: " + count + ")"
+	}
+	return count
+}
+
+// Interpolate returns the count of the state expression interpolated in a
+// Dart string.
+func (d *sampleData) Interpolate(state string) string {
+	if d.StateClass {
+		return "${" + state + ".count}"
+	}
+	return "$" + state
+}
+
+// appendMissing appends the packages not already in the list.
+func appendMissing(packages []string, extra ...string) []string {
+	result := append([]string(nil), packages...)
+	for _, pkg := range extra {
+		found := false
+		for _, existing := range result {
+			if existing == pkg {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultGitBranch        string = "main"
+	defaultGitCommitMessage string = "Initial commit"
+)
+
+func initializeGitRepository(projectPath string, opts projectOptions) {
+	if _, err := exec.LookPath("git"); err != nil {
+		fmt.Println("git is not installed, skipping repository initialization.")
+		return
+	}
+
+	// Never move the branch of a repository the project was generated into,
+	// nor nest a repository in the work tree of another one
+	if insideGitWorkTree(projectPath) {
+		fmt.Println("The project is already inside a git repository, skipping repository initialization.")
+		return
+	}
+
+	branch := strings.TrimSpace(opts.GitBranch)
+	if branch == "" {
+		branch = defaultGitBranch
+	}
+	message := strings.TrimSpace(opts.GitCommitMessage)
+	if message == "" {
+		message = defaultGitCommitMessage
+	}
+
+	// The build_runner outputs are regenerated by the CI pipelines
+	if usesCodegen(opts) {
+		addGitIgnoreEntries(projectPath, "Generated code", generatedFiles(opts))
+	}
+
+	// Pointing HEAD at the branch before the first commit works on every
+	// git version, unlike git init --initial-branch
+	cmd1 := exec.Command("git", "init")
+	cmd1.Dir = projectPath
+	executeCommand(cmd1)
+
+	cmd2 := exec.Command("git", "symbolic-ref", "HEAD", "refs/heads/"+branch)
+	cmd2.Dir = projectPath
+	executeCommand(cmd2)
+
+	cmd3 := exec.Command("git", "add", "-A")
+	cmd3.Dir = projectPath
+	executeCommand(cmd3)
+
+	cmd4 := exec.Command("git", "commit", "-m", message)
+	cmd4.Dir = projectPath
+	executeCommand(cmd4)
+}
+
+// insideGitWorkTree reports whether dir is part of the work tree of a git
+// repository, its own or one of a parent directory.
+func insideGitWorkTree(dir string) bool {
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// addGitIgnoreEntries appends the patterns missing from the project's
+// .gitignore under a comment.
+func addGitIgnoreEntries(projectPath, comment string, patterns []string) {
+	gitignorePath := filepath.Join(projectPath, ".gitignore")
+	content, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Println("Error reading .gitignore:", err)
+		return
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, pattern := range patterns {
+		if !existing[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	updated := string(content)
+	if updated != "" && !strings.HasSuffix(updated, "\n") {
+		updated += "\n"
+	}
+	updated += "\n# " + comment + "\n" + strings.Join(missing, "\n") + "\n"
+	createFile(gitignorePath, updated)
+}
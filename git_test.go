@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsideGitWorkTree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	outside := t.TempDir()
+	if insideGitWorkTree(outside) {
+		t.Skipf("%s is already inside a git work tree", outside)
+	}
+
+	repo := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	nested := filepath.Join(repo, "apps", "demo")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !insideGitWorkTree(repo) || !insideGitWorkTree(nested) {
+		t.Error("the repository and its subfolders are inside a work tree")
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Graph formats
+const (
+	DOTFormat     string = "dot"
+	MermaidFormat string = "mermaid"
+)
+
+// Kinds of graph nodes
+const (
+	fileNode    string = "file"
+	folderNode  string = "folder"
+	packageNode string = "package"
+)
+
+// graphNode is a Dart file, a collapsed folder or another package.
+type graphNode struct {
+	ID string `json:"id"`
+	// Folder is the folder the node is drawn in, empty for lib/ itself and
+	// for packages
+	Folder string `json:"folder"`
+	Kind   string `json:"kind"`
+}
+
+// graphEdge counts the imports from a node to another.
+type graphEdge struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Imports int    `json:"imports"`
+}
+
+// dependencyGraph is the import graph of lib/.
+type dependencyGraph struct {
+	Package string      `json:"package"`
+	Nodes   []graphNode `json:"nodes"`
+	Edges   []graphEdge `json:"edges"`
+}
+
+func graphCommand(args []string) {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	project := flags.String("project", ".", "path of the Flutter project")
+	format := flags.String("format", DOTFormat, "output format: dot, mermaid or json")
+	collapse := flags.Int("collapse", 0, "collapse the files into their folders, keeping that many folder levels under lib/ (0 keeps every file)")
+	packages := flags.Bool("packages", false, "include the imported packages")
+	output := flags.String("output", "", "file to write the graph to (standard output by default)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch graph [flags]")
+		flags.PrintDefaults()
+	}
+	if positional := parseInterspersed(flags, args); len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+	if *collapse < 0 {
+		fmt.Println("--collapse must not be negative.")
+		return
+	}
+
+	projectPath := *project
+	files, imports, err := scanDartImports(projectPath)
+	if err != nil {
+		fmt.Println("Error reading lib directory:", err)
+		return
+	}
+	graph := buildGraph(pubspecName(projectPath), files, imports, *collapse, *packages)
+
+	var content string
+	switch *format {
+	case DOTFormat:
+		content = graph.dot()
+	case MermaidFormat:
+		content = graph.mermaid()
+	case JSONFormat:
+		encoded, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			fmt.Println("Error encoding graph:", err)
+			return
+		}
+		content = string(encoded) + "\n"
+	default:
+		fmt.Printf("Graph format %s is not supported.\n", *format)
+		return
+	}
+
+	if *output == "" {
+		fmt.Print(content)
+		return
+	}
+	if err := os.WriteFile(*output, []byte(content), 0644); err != nil {
+		fmt.Println("Error writing graph:", err)
+		return
+	}
+	fmt.Printf("Graph of %d nodes and %d edges written to %s\n", len(graph.Nodes), len(graph.Edges), *output)
+}
+
+// buildGraph builds the graph of the files and their imports. With collapse
+// above 0, files deeper than collapse folders are merged into the folder at
+// that depth. Imports of other packages are nodes named after the package
+// when packages is set, and left out otherwise.
+func buildGraph(packageName string, files []string, imports []dartImport, collapse int, packages bool) dependencyGraph {
+	graph := dependencyGraph{Package: packageName}
+	nodes := make(map[string]graphNode)
+	node := func(file string) string {
+		if strings.HasPrefix(file, "package:") {
+			name, _, _ := strings.Cut(file, "/")
+			nodes[name] = graphNode{ID: name, Kind: packageNode}
+			return name
+		}
+		segments := strings.Split(file, "/")
+		if collapse > 0 && len(segments) > collapse {
+			id := strings.Join(segments[:collapse], "/")
+			nodes[id] = graphNode{ID: id, Folder: path.Dir(id), Kind: folderNode}
+			return id
+		}
+		nodes[file] = graphNode{ID: file, Folder: path.Dir(file), Kind: fileNode}
+		return file
+	}
+
+	for _, file := range files {
+		node(file)
+	}
+	counts := make(map[[2]string]int)
+	for _, imp := range imports {
+		if strings.HasPrefix(imp.Target, "package:") && !packages {
+			continue
+		}
+		from, to := node(imp.File), node(imp.Target)
+		if from != to {
+			counts[[2]string{from, to}]++
+		}
+	}
+
+	for _, n := range nodes {
+		if n.Folder == "." {
+			n.Folder = ""
+		}
+		graph.Nodes = append(graph.Nodes, n)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	for edge, count := range counts {
+		graph.Edges = append(graph.Edges, graphEdge{From: edge[0], To: edge[1], Imports: count})
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph
+}
+
+// folders returns the nodes by folder, with the folders sorted.
+func (g dependencyGraph) folders() ([]string, map[string][]graphNode) {
+	byFolder := make(map[string][]graphNode)
+	for _, n := range g.Nodes {
+		byFolder[n.Folder] = append(byFolder[n.Folder], n)
+	}
+	var folders []string
+	for folder := range byFolder {
+		folders = append(folders, folder)
+	}
+	sort.Strings(folders)
+	return folders, byFolder
+}
+
+// dot renders the graph in the Graphviz DOT language, with a cluster per
+// folder.
+func (g dependencyGraph) dot() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", g.Package)
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"Helvetica\"];\n")
+
+	folders, byFolder := g.folders()
+	for i, folder := range folders {
+		indent := "  "
+		if folder != "" {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(&b, "    label=%q;\n", folder+"/")
+			indent = "    "
+		}
+		for _, n := range byFolder[folder] {
+			label := path.Base(n.ID)
+			switch n.Kind {
+			case folderNode:
+				fmt.Fprintf(&b, "%s%q [label=%q, shape=folder];\n", indent, n.ID, label+"/")
+			case packageNode:
+				fmt.Fprintf(&b, "%s%q [label=%q, shape=ellipse, style=dashed];\n", indent, n.ID, n.ID)
+			default:
+				fmt.Fprintf(&b, "%s%q [label=%q];\n", indent, n.ID, label)
+			}
+		}
+		if folder != "" {
+			b.WriteString("  }\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.Imports > 1 {
+			fmt.Fprintf(&b, "  %q -> %q [label=\"%d\"];\n", e.From, e.To, e.Imports)
+		} else {
+			fmt.Fprintf(&b, "  %q -> %q;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// mermaid renders the graph as a Mermaid flowchart, with a subgraph per
+// folder. Mermaid ids cannot hold slashes, so nodes are numbered.
+func (g dependencyGraph) mermaid() string {
+	ids := make(map[string]string)
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	folders, byFolder := g.folders()
+	for i, folder := range folders {
+		indent := "  "
+		if folder != "" {
+			fmt.Fprintf(&b, "  subgraph f%d [\"%s/\"]\n", i, folder)
+			indent = "    "
+		}
+		for _, n := range byFolder[folder] {
+			label := path.Base(n.ID)
+			switch n.Kind {
+			case folderNode:
+				fmt.Fprintf(&b, "%s%s[[\"%s/\"]]\n", indent, ids[n.ID], label)
+			case packageNode:
+				fmt.Fprintf(&b, "%s%s([\"%s\"])\n", indent, ids[n.ID], n.ID)
+			default:
+				fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, ids[n.ID], label)
+			}
+		}
+		if folder != "" {
+			b.WriteString("  end\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.Imports > 1 {
+			fmt.Fprintf(&b, "  %s -->|%d| %s\n", ids[e.From], e.Imports, ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[e.From], ids[e.To])
+		}
+	}
+	return b.String()
+}
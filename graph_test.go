@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// scanGraphProject writes a small lib tree and returns its files and imports.
+func scanGraphProject(t *testing.T) ([]string, []dartImport) {
+	t.Helper()
+	project := t.TempDir()
+	writeTestFiles(t, project, map[string]string{
+		"pubspec.yaml": "name: demo\n",
+		"lib/main.dart": "import 'package:demo/app.dart';\n" +
+			"import 'package:flutter/material.dart';\n",
+		"lib/app.dart": "import 'features/counter/counter_page.dart';\n" +
+			"import 'package:flutter/material.dart';\n",
+		"lib/features/counter/counter_page.dart": "import 'package:flutter/material.dart';\n" +
+			"import 'package:provider/provider.dart';\n" +
+			"import 'counter_store.dart';\n" +
+			"export 'counter_store.dart';\n",
+		"lib/features/counter/counter_store.dart": "import 'dart:async';\n" +
+			"import 'package:flutter/foundation.dart';\n" +
+			"import '../../core/api.dart';\n",
+		"lib/core/api.dart": "import 'package:http/http.dart';\n",
+	})
+	files, imports, err := scanDartImports(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files, imports
+}
+
+// describeGraph lists the nodes as "id(kind)" and the edges as
+// "from->to:imports".
+func describeGraph(g dependencyGraph) (string, string) {
+	var nodes, edges []string
+	for _, n := range g.Nodes {
+		nodes = append(nodes, fmt.Sprintf("%s(%s)", n.ID, n.Kind))
+	}
+	for _, e := range g.Edges {
+		edges = append(edges, fmt.Sprintf("%s->%s:%d", e.From, e.To, e.Imports))
+	}
+	return strings.Join(nodes, " "), strings.Join(edges, " ")
+}
+
+// keepPackageImports leaves out the imports of other packages but uri.
+func keepPackageImports(imports []dartImport, uri string) []dartImport {
+	var kept []dartImport
+	for _, imp := range imports {
+		if !strings.HasPrefix(imp.Target, "package:") || imp.Target == uri {
+			kept = append(kept, imp)
+		}
+	}
+	return kept
+}
+
+func TestBuildGraph(t *testing.T) {
+	files, imports := scanGraphProject(t)
+	tests := []struct {
+		name     string
+		collapse int
+		packages bool
+		nodes    string
+		edges    string
+	}{
+		{
+			name:  "files",
+			nodes: "app.dart(file) core/api.dart(file) features/counter/counter_page.dart(file) features/counter/counter_store.dart(file) main.dart(file)",
+			edges: "app.dart->features/counter/counter_page.dart:1 features/counter/counter_page.dart->features/counter/counter_store.dart:2 " +
+				"features/counter/counter_store.dart->core/api.dart:1 main.dart->app.dart:1",
+		},
+		{
+			name:     "collapsed to the first level",
+			collapse: 1,
+			nodes:    "app.dart(file) core(folder) features(folder) main.dart(file)",
+			edges:    "app.dart->features:1 features->core:1 main.dart->app.dart:1",
+		},
+		{
+			name:     "collapsed to the second level",
+			collapse: 2,
+			nodes:    "app.dart(file) core/api.dart(file) features/counter(folder) main.dart(file)",
+			edges:    "app.dart->features/counter:1 features/counter->core/api.dart:1 main.dart->app.dart:1",
+		},
+		{
+			name:     "packages",
+			collapse: 1,
+			packages: true,
+			nodes:    "app.dart(file) core(folder) features(folder) main.dart(file) package:flutter(package) package:http(package) package:provider(package)",
+			edges: "app.dart->features:1 app.dart->package:flutter:1 core->package:http:1 features->core:1 " +
+				"features->package:flutter:2 features->package:provider:1 main.dart->app.dart:1 main.dart->package:flutter:1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, edges := describeGraph(buildGraph("demo", files, imports, tt.collapse, tt.packages))
+			if nodes != tt.nodes {
+				t.Errorf("nodes:\n got %s\nwant %s", nodes, tt.nodes)
+			}
+			if edges != tt.edges {
+				t.Errorf("edges:\n got %s\nwant %s", edges, tt.edges)
+			}
+		})
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	files, imports := scanGraphProject(t)
+	want := `digraph "demo" {
+  rankdir=LR;
+  node [shape=box, fontname="Helvetica"];
+  "app.dart" [label="app.dart"];
+  "main.dart" [label="main.dart"];
+  subgraph cluster_1 {
+    label="core/";
+    "core/api.dart" [label="api.dart"];
+  }
+  subgraph cluster_2 {
+    label="features/counter/";
+    "features/counter/counter_page.dart" [label="counter_page.dart"];
+    "features/counter/counter_store.dart" [label="counter_store.dart"];
+  }
+  "app.dart" -> "features/counter/counter_page.dart";
+  "features/counter/counter_page.dart" -> "features/counter/counter_store.dart" [label="2"];
+  "features/counter/counter_store.dart" -> "core/api.dart";
+  "main.dart" -> "app.dart";
+}
+`
+	if got := buildGraph("demo", files, imports, 0, false).dot(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	want = `digraph "demo" {
+  rankdir=LR;
+  node [shape=box, fontname="Helvetica"];
+  "app.dart" [label="app.dart"];
+  "core" [label="core/", shape=folder];
+  "features" [label="features/", shape=folder];
+  "main.dart" [label="main.dart"];
+  "package:http" [label="package:http", shape=ellipse, style=dashed];
+  "app.dart" -> "features";
+  "core" -> "package:http";
+  "features" -> "core";
+  "main.dart" -> "app.dart";
+}
+`
+	packageImports := keepPackageImports(imports, "package:http/http.dart")
+	if got := buildGraph("demo", files, packageImports, 1, true).dot(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	files, imports := scanGraphProject(t)
+	want := `flowchart LR
+  n0["app.dart"]
+  n4["main.dart"]
+  subgraph f1 ["core/"]
+    n1["api.dart"]
+  end
+  subgraph f2 ["features/counter/"]
+    n2["counter_page.dart"]
+    n3["counter_store.dart"]
+  end
+  n0 --> n2
+  n2 -->|2| n3
+  n3 --> n1
+  n4 --> n0
+`
+	if got := buildGraph("demo", files, imports, 0, false).mermaid(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	want = `flowchart LR
+  n0["app.dart"]
+  n1[["core/"]]
+  n2[["features/"]]
+  n3["main.dart"]
+  n4(["package:http"])
+  n0 --> n2
+  n1 --> n4
+  n2 --> n1
+  n3 --> n0
+`
+	packageImports := keepPackageImports(imports, "package:http/http.dart")
+	if got := buildGraph("demo", files, packageImports, 1, true).mermaid(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lintConfigFile is the name of the optional configuration of lint-arch, at
+// the root of the project.
+const lintConfigFile string = ".flutter-arch-lint.yaml"
+
+// Output formats of lint-arch
+const (
+	TextFormat   string = "text"
+	JSONFormat   string = "json"
+	GitHubFormat string = "github"
+)
+
+var dartDirectivePattern = regexp.MustCompile(`(?m)^\s*(import|export)\s+['"]([^'"]+)['"]`)
+
+// layerRule forbids the files matching From to import the files matching one
+// of To. Patterns are slash-separated paths relative to lib/, or package:
+// URIs of other packages, where * matches one path segment, ** any number of
+// segments, {name} captures a segment and {!name} matches any segment but
+// the captured one.
+type layerRule struct {
+	Name    string   `yaml:"name" json:"name"`
+	From    string   `yaml:"from" json:"from"`
+	To      []string `yaml:"to" json:"to"`
+	Message string   `yaml:"message" json:"message"`
+}
+
+// layerRules are the default rules of each layout pattern.
+var layerRules = map[string][]layerRule{
+	CleanArchitecture: {
+		{
+			Name:    "domain-independence",
+			From:    "features/*/domain/**",
+			To:      []string{"features/*/data/**", "features/*/presentation/**"},
+			Message: "the domain layer must not depend on the data or presentation layers",
+		},
+		{
+			Name:    "data-presentation",
+			From:    "features/*/data/**",
+			To:      []string{"features/*/presentation/**"},
+			Message: "the data layer must not depend on the presentation layer",
+		},
+		{
+			Name:    "presentation-data",
+			From:    "features/*/presentation/**",
+			To:      []string{"features/*/data/**"},
+			Message: "the presentation layer must go through the domain layer, not the data layer",
+		},
+		{
+			Name:    "feature-internals",
+			From:    "features/{feature}/**",
+			To:      []string{"features/{!feature}/data/**", "features/{!feature}/presentation/**"},
+			Message: "features may only use the domain layer of other features",
+		},
+		{
+			Name:    "core-independence",
+			From:    "core/**",
+			To:      []string{"features/**"},
+			Message: "core must not depend on features",
+		},
+	},
+	Mvvm: {
+		{
+			Name:    "model-independence",
+			From:    "model/**",
+			To:      []string{"viewmodel/**", "view/**"},
+			Message: "models must not depend on view models or views",
+		},
+		{
+			Name:    "viewmodel-view",
+			From:    "viewmodel/**",
+			To:      []string{"view/**"},
+			Message: "view models must not depend on views",
+		},
+		{
+			Name:    "view-repository",
+			From:    "view/**",
+			To:      []string{"**/*repository*.dart"},
+			Message: "views must get their data from view models, not from repositories",
+		},
+	},
+	Mvc: {
+		{
+			Name:    "model-independence",
+			From:    "model/**",
+			To:      []string{"controller/**", "view/**"},
+			Message: "models must not depend on controllers or views",
+		},
+		{
+			Name:    "view-repository",
+			From:    "view/**",
+			To:      []string{"**/*repository*.dart"},
+			Message: "views must get their data from controllers, not from repositories",
+		},
+	},
+}
+
+// lintConfig is the layout of .flutter-arch-lint.yaml.
+type lintConfig struct {
+	// Architecture overrides the layout pattern detected from lib/
+	Architecture string `yaml:"architecture"`
+	// Disable lists the default rules to turn off
+	Disable []string `yaml:"disable"`
+	// Rules are added to the default rules
+	Rules []layerRule `yaml:"rules"`
+}
+
+// layerViolation is an import breaking a rule.
+type layerViolation struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Import  string `json:"import"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func lintArchCommand(args []string) {
+	flags := flag.NewFlagSet("lint-arch", flag.ExitOnError)
+	project := flags.String("project", ".", "path of the Flutter project")
+	format := flags.String("format", TextFormat, "output format: text, json or github (workflow command annotations)")
+	configPath := flags.String("config", "", "rules configuration (default <project>/"+lintConfigFile+")")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch lint-arch [flags]")
+		flags.PrintDefaults()
+	}
+	if positional := parseInterspersed(flags, args); len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+	switch *format {
+	case TextFormat, JSONFormat, GitHubFormat:
+	default:
+		fmt.Printf("Output format %s is not supported.\n", *format)
+		return
+	}
+
+	projectPath := *project
+	config, err := readLintConfig(projectPath, *configPath)
+	if err != nil {
+		fmt.Println("Error reading lint configuration:", err)
+		return
+	}
+	pattern, err := lintPattern(projectPath, config)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	rules := lintRules(pattern, config)
+
+	violations, err := lintLayers(projectPath, rules)
+	if err != nil {
+		fmt.Println("Error checking imports:", err)
+		return
+	}
+
+	writeLintReport(os.Stdout, *format, pattern, rules, violations)
+
+	// Report the violations through the exit status, for CI
+	if len(violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeLintReport writes the violations of the rules in the given format.
+func writeLintReport(w io.Writer, format, pattern string, rules []layerRule, violations []layerViolation) {
+	switch format {
+	case JSONFormat:
+		report := struct {
+			Architecture string           `json:"architecture"`
+			Rules        []layerRule      `json:"rules"`
+			Violations   []layerViolation `json:"violations"`
+		}{shortName(pattern), rules, violations}
+		if report.Rules == nil {
+			report.Rules = []layerRule{}
+		}
+		if report.Violations == nil {
+			report.Violations = []layerViolation{}
+		}
+		content, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Fprintln(w, string(content))
+	case GitHubFormat:
+		for _, v := range violations {
+			fmt.Fprintf(w, "::error file=%s,line=%d,title=%s::%s (imports %s)\n", v.File, v.Line, v.Rule, v.Message, v.Import)
+		}
+	default:
+		if len(rules) == 0 {
+			fmt.Fprintf(w, "No layering rules for %s, add some in %s.\n", shortName(pattern), lintConfigFile)
+			return
+		}
+		for _, v := range violations {
+			fmt.Fprintf(w, "%s:%d: %s: %s (imports %s)\n", v.File, v.Line, v.Rule, v.Message, v.Import)
+		}
+		if len(violations) == 0 {
+			fmt.Fprintf(w, "No layering violations, %d %s rules checked.\n", len(rules), shortName(pattern))
+		} else {
+			fmt.Fprintf(w, "%d layering violations.\n", len(violations))
+		}
+	}
+}
+
+// readLintConfig reads the configuration of lint-arch, an empty one when the
+// project has none.
+func readLintConfig(projectPath, configPath string) (lintConfig, error) {
+	var config lintConfig
+	if configPath == "" {
+		configPath = filepath.Join(projectPath, lintConfigFile)
+		if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+	}
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("%s: %w", configPath, err)
+	}
+	for _, rule := range config.Rules {
+		if rule.Name == "" || rule.From == "" || len(rule.To) == 0 {
+			return config, fmt.Errorf("%s: every rule needs a name, from and to", configPath)
+		}
+	}
+	return config, nil
+}
+
+// lintPattern returns the layout pattern the configuration names, or else the
+// one recorded in the manifest or detected from lib/.
+func lintPattern(projectPath string, config lintConfig) (string, error) {
+	if config.Architecture != "" {
+		for _, pattern := range patterns {
+			if strings.EqualFold(config.Architecture, shortName(pattern)) || config.Architecture == pattern {
+				return pattern, nil
+			}
+		}
+		return "", fmt.Errorf("unknown architecture %s in %s", config.Architecture, lintConfigFile)
+	}
+	if manifest, err := readManifest(projectPath); err == nil && manifest.Options.Pattern != "" {
+		return manifest.Options.Pattern, nil
+	}
+	return detectPattern(projectPath), nil
+}
+
+// lintRules returns the default rules of the pattern but the disabled ones,
+// followed by the rules of the configuration.
+func lintRules(pattern string, config lintConfig) []layerRule {
+	disabled := make(map[string]bool)
+	for _, name := range config.Disable {
+		disabled[name] = true
+	}
+	var rules []layerRule
+	for _, rule := range layerRules[pattern] {
+		if !disabled[rule.Name] {
+			rules = append(rules, rule)
+		}
+	}
+	return append(rules, config.Rules...)
+}
+
+// lintLayers checks the import and export directives of the Dart files under
+// lib/ against the rules.
+func lintLayers(projectPath string, rules []layerRule) ([]layerViolation, error) {
+	_, imports, err := scanDartImports(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []layerViolation
+	for _, imp := range imports {
+		for _, rule := range rules {
+			if ruleMatches(rule, imp.File, imp.Target) {
+				violations = append(violations, layerViolation{
+					File:    path.Join("lib", imp.File),
+					Line:    imp.Line,
+					Import:  imp.URI,
+					Rule:    rule.Name,
+					Message: rule.Message,
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// dartImport is an import or export directive of a Dart file under lib/.
+type dartImport struct {
+	// File is the path of the importing file, relative to lib/
+	File string
+	Line int
+	URI  string
+	// Target is the path of the imported file relative to lib/, or the
+	// package: URI of another package
+	Target string
+}
+
+// scanDartImports returns the Dart files under lib/, relative to it, and
+// their import and export directives, sorted by file and line. dart:
+// libraries are left out.
+func scanDartImports(projectPath string) ([]string, []dartImport, error) {
+	packageName := pubspecName(projectPath)
+	lib := filepath.Join(projectPath, "lib")
+
+	var files []string
+	var imports []dartImport
+	err := filepath.WalkDir(lib, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(filePath, ".dart") {
+			return nil
+		}
+		rel, err := filepath.Rel(lib, filePath)
+		if err != nil {
+			return err
+		}
+		file := filepath.ToSlash(rel)
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+
+		for _, match := range dartDirectivePattern.FindAllStringSubmatchIndex(string(content), -1) {
+			uri := string(content[match[4]:match[5]])
+			target, ok := importTarget(packageName, file, uri)
+			if !ok {
+				continue
+			}
+			imports = append(imports, dartImport{
+				File:   file,
+				Line:   strings.Count(string(content[:match[4]]), "\n") + 1,
+				URI:    uri,
+				Target: target,
+			})
+		}
+		return nil
+	})
+	return files, imports, err
+}
+
+// importTarget resolves the URI imported by file, both relative to lib/, to
+// the path of the imported file relative to lib/, or to the package: URI of
+// another package. dart: libraries are left out.
+func importTarget(packageName, file, uri string) (string, bool) {
+	switch {
+	case strings.HasPrefix(uri, "dart:"):
+		return "", false
+	case strings.HasPrefix(uri, "package:"+packageName+"/"):
+		return strings.TrimPrefix(uri, "package:"+packageName+"/"), true
+	case strings.HasPrefix(uri, "package:"):
+		return uri, true
+	}
+	target := path.Join(path.Dir(file), uri)
+	if strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// ruleMatches reports whether importing target from file breaks the rule.
+func ruleMatches(rule layerRule, file, target string) bool {
+	captures := make(map[string]string)
+	if !matchLayerPattern(strings.Split(rule.From, "/"), strings.Split(file, "/"), captures) {
+		return false
+	}
+	for _, to := range rule.To {
+		if matchLayerPattern(strings.Split(to, "/"), strings.Split(target, "/"), copyCaptures(captures)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLayerPattern matches the segments of a path against the segments of
+// a pattern, recording the captured segments.
+func matchLayerPattern(pattern, segments []string, captures map[string]string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(segments); skip++ {
+			attempt := copyCaptures(captures)
+			if matchLayerPattern(pattern[1:], segments[skip:], attempt) {
+				for name, value := range attempt {
+					captures[name] = value
+				}
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+
+	segment := pattern[0]
+	switch {
+	case strings.HasPrefix(segment, "{!") && strings.HasSuffix(segment, "}"):
+		name := segment[2 : len(segment)-1]
+		if captured, ok := captures[name]; ok && captured == segments[0] {
+			return false
+		}
+	case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+		name := segment[1 : len(segment)-1]
+		if captured, ok := captures[name]; ok && captured != segments[0] {
+			return false
+		}
+		captures[name] = segments[0]
+	default:
+		if ok, _ := path.Match(segment, segments[0]); !ok {
+			return false
+		}
+	}
+	return matchLayerPattern(pattern[1:], segments[1:], captures)
+}
+
+func copyCaptures(captures map[string]string) map[string]string {
+	copied := make(map[string]string, len(captures))
+	for name, value := range captures {
+		copied[name] = value
+	}
+	return copied
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchLayerPattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"features/*/domain/**", "features/auth/domain/entities/user.dart", true},
+		{"features/*/domain/**", "features/auth/data/user_model.dart", false},
+		{"core/**", "core", true},
+		{"core/**", "core/network/api_client.dart", true},
+		{"core/**", "features/auth/core.dart", false},
+		{"**/*repository*.dart", "user_repository.dart", true},
+		{"**/*repository*.dart", "data/repositories/user_repository_impl.dart", true},
+		{"**/*repository*.dart", "data/repositories/user_source.dart", false},
+		{"{feature}/{feature}", "auth/auth", true},
+		{"{feature}/{feature}", "auth/cart", false},
+		{"{feature}/{!feature}", "auth/cart", true},
+		{"{feature}/{!feature}", "auth/auth", false},
+		{"{!feature}/x.dart", "auth/x.dart", true},
+		{"package:*/**", "package:http/http.dart", true},
+	}
+	for _, tt := range tests {
+		got := matchLayerPattern(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/"), make(map[string]string))
+		if got != tt.want {
+			t.Errorf("%s matching %s = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRuleMatchesFeatureInternals(t *testing.T) {
+	var rule layerRule
+	for _, r := range layerRules[CleanArchitecture] {
+		if r.Name == "feature-internals" {
+			rule = r
+		}
+	}
+	tests := []struct {
+		file, target string
+		want         bool
+	}{
+		// Another feature's data and presentation layers are off limits
+		{"features/cart/presentation/cart_page.dart", "features/auth/data/user_repository_impl.dart", true},
+		{"features/cart/domain/usecases/checkout.dart", "features/auth/presentation/login_page.dart", true},
+		// Its domain layer is not
+		{"features/cart/presentation/cart_page.dart", "features/auth/domain/entities/user.dart", false},
+		// The rule leaves the feature's own layers to the other rules
+		{"features/cart/presentation/cart_page.dart", "features/cart/data/cart_repository_impl.dart", false},
+		{"core/network/api_client.dart", "features/auth/data/user_repository_impl.dart", false},
+	}
+	for _, tt := range tests {
+		if got := ruleMatches(rule, tt.file, tt.target); got != tt.want {
+			t.Errorf("%s importing %s = %v, want %v", tt.file, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestImportTarget(t *testing.T) {
+	tests := []struct {
+		file, uri string
+		want      string
+		ok        bool
+	}{
+		{"features/auth/presentation/login_page.dart", "../domain/entities/user.dart", "features/auth/domain/entities/user.dart", true},
+		{"features/auth/presentation/login_page.dart", "widgets/login_form.dart", "features/auth/presentation/widgets/login_form.dart", true},
+		{"features/auth/presentation/login_page.dart", "../../../core/error.dart", "core/error.dart", true},
+		{"main.dart", "../test/helpers.dart", "", false},
+		{"main.dart", "package:demo/app.dart", "app.dart", true},
+		{"main.dart", "package:http/http.dart", "package:http/http.dart", true},
+		{"main.dart", "dart:async", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := importTarget("demo", tt.file, tt.uri)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s from %s = %q %v, want %q %v", tt.uri, tt.file, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// writeLintProject writes a Clean Architecture project breaking the layers
+// with a cross-feature import and a relative import of the data layer.
+func writeLintProject(t *testing.T, config string) string {
+	t.Helper()
+	project := t.TempDir()
+	files := map[string]string{
+		"pubspec.yaml": "name: demo\n",
+		"lib/features/auth/domain/entities/user.dart":      "class User {}\n",
+		"lib/features/auth/data/user_repository_impl.dart": "import 'package:http/http.dart';\n",
+		"lib/features/cart/domain/entities/cart.dart":      "import 'package:demo/features/auth/domain/entities/user.dart';\n",
+		"lib/features/cart/presentation/cart_page.dart": "import 'dart:async';\n" +
+			"import 'package:demo/features/auth/data/user_repository_impl.dart';\n" +
+			"import '../data/cart_repository_impl.dart';\n",
+		"lib/features/cart/data/cart_repository_impl.dart": "import '../domain/entities/cart.dart';\n",
+	}
+	if config != "" {
+		files[lintConfigFile] = config
+	}
+	writeTestFiles(t, project, files)
+	return project
+}
+
+func TestLintLayers(t *testing.T) {
+	project := writeLintProject(t, "")
+	config, err := readLintConfig(project, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations, err := lintLayers(project, lintRules(CleanArchitecture, config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.File+":"+v.Rule+":"+v.Import)
+	}
+	want := []string{
+		"lib/features/cart/presentation/cart_page.dart:presentation-data:package:demo/features/auth/data/user_repository_impl.dart",
+		"lib/features/cart/presentation/cart_page.dart:feature-internals:package:demo/features/auth/data/user_repository_impl.dart",
+		"lib/features/cart/presentation/cart_page.dart:presentation-data:../data/cart_repository_impl.dart",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if violations[0].Line != 2 || violations[2].Line != 3 {
+		t.Errorf("lines = %d, %d, want 2, 3", violations[0].Line, violations[2].Line)
+	}
+}
+
+func TestLintConfigDisableAndRules(t *testing.T) {
+	project := writeLintProject(t, `disable:
+  - presentation-data
+rules:
+  - name: no-http-in-data
+    from: features/*/data/**
+    to:
+      - package:http/**
+    message: the data layer goes through core/network
+`)
+	config, err := readLintConfig(project, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := lintRules(CleanArchitecture, config)
+	for _, rule := range rules {
+		if rule.Name == "presentation-data" {
+			t.Error("presentation-data is disabled")
+		}
+	}
+	if last := rules[len(rules)-1]; last.Name != "no-http-in-data" {
+		t.Errorf("the rules of the configuration should follow the defaults, last is %s", last.Name)
+	}
+
+	violations, err := lintLayers(project, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.File+":"+v.Rule)
+	}
+	want := "lib/features/auth/data/user_repository_impl.dart:no-http-in-data\n" +
+		"lib/features/cart/presentation/cart_page.dart:feature-internals"
+	if strings.Join(got, "\n") != want {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), want)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "lint.yaml")
+	writeTestFiles(t, filepath.Dir(invalid), map[string]string{"lint.yaml": "rules:\n  - name: incomplete\n"})
+	if _, err := readLintConfig(project, invalid); err == nil {
+		t.Error("a rule without from and to should be rejected")
+	}
+}
+
+func TestWriteLintReport(t *testing.T) {
+	rules := layerRules[CleanArchitecture][:1]
+	violations := []layerViolation{{
+		File:    "lib/features/auth/domain/entities/user.dart",
+		Line:    3,
+		Import:  "../../data/user_model.dart",
+		Rule:    "domain-independence",
+		Message: "the domain layer must not depend on the data or presentation layers",
+	}}
+
+	var github bytes.Buffer
+	writeLintReport(&github, GitHubFormat, CleanArchitecture, rules, violations)
+	want := "::error file=lib/features/auth/domain/entities/user.dart,line=3,title=domain-independence::" +
+		"the domain layer must not depend on the data or presentation layers (imports ../../data/user_model.dart)\n"
+	if github.String() != want {
+		t.Errorf("github:\n%s\nwant:\n%s", github.String(), want)
+	}
+
+	var out bytes.Buffer
+	writeLintReport(&out, JSONFormat, CleanArchitecture, rules, violations)
+	var report struct {
+		Architecture string           `json:"architecture"`
+		Rules        []layerRule      `json:"rules"`
+		Violations   []layerViolation `json:"violations"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("json: %v\n%s", err, out.String())
+	}
+	if report.Architecture != CleanArchitecture || len(report.Rules) != 1 || len(report.Violations) != 1 || report.Violations[0] != violations[0] {
+		t.Errorf("json report = %+v", report)
+	}
+
+	// Empty lists stay lists for the tools reading the report
+	out.Reset()
+	writeLintReport(&out, JSONFormat, Mvc, nil, nil)
+	if !strings.Contains(out.String(), `"rules": []`) || !strings.Contains(out.String(), `"violations": []`) {
+		t.Errorf("json report without violations:\n%s", out.String())
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	FlutterLints     string = "flutter_lints"
+	Lints            string = "lints"
+	VeryGoodAnalysis string = "very_good_analysis"
+	CustomLints      string = "Custom analysis_options.yaml file"
+)
+
+// List of lint presets
+var lintPresets = []string{
+	FlutterLints,
+	Lints,
+	VeryGoodAnalysis,
+	CustomLints,
+}
+
+// analyzerOptions keeps build_runner outputs out of the analyzer, they are
+// not written by hand and cannot be expected to follow the preset, and
+// enables the given analyzer plugins.
+func analyzerOptions(generated, plugins []string) string {
+	var b strings.Builder
+	b.WriteString("\nanalyzer:\n  exclude:\n")
+	for _, pattern := range generated {
+		fmt.Fprintf(&b, "    - \"**/%s\"\n", pattern)
+	}
+	if len(plugins) > 0 {
+		b.WriteString("  plugins:\n")
+		for _, plugin := range plugins {
+			fmt.Fprintf(&b, "    - %s\n", plugin)
+		}
+	}
+	return b.String()
+}
+
+var lintIncludePattern = regexp.MustCompile(`(?m)^include:\s*package:([a-z0-9_]+)/`)
+
+func addLintPreset(projectPath, preset, customFile string, generated, plugins []string) {
+	var lintPackage, analysisOptions string
+
+	switch preset {
+	case FlutterLints, "":
+		lintPackage = FlutterLints
+		analysisOptions = "include: package:flutter_lints/flutter.yaml\n" + analyzerOptions(generated, plugins)
+	case Lints:
+		lintPackage = Lints
+		analysisOptions = "include: package:lints/recommended.yaml\n" + analyzerOptions(generated, plugins)
+	case VeryGoodAnalysis:
+		lintPackage = VeryGoodAnalysis
+		analysisOptions = "include: package:very_good_analysis/analysis_options.yaml\n" + analyzerOptions(generated, plugins) + `
+linter:
+  rules:
+    public_member_api_docs: false
+`
+	case CustomLints:
+		content, err := os.ReadFile(customFile)
+		if err != nil {
+			fmt.Println("Error reading lint file:", err)
+			return
+		}
+		analysisOptions = string(content)
+		if match := lintIncludePattern.FindStringSubmatch(analysisOptions); match != nil {
+			lintPackage = match[1]
+		}
+	default:
+		fmt.Printf("Lint preset %s is not supported yet.\n", preset)
+		return
+	}
+
+	// flutter create already depends on flutter_lints, swap it for the
+	// package the preset includes
+	if lintPackage != "" && lintPackage != FlutterLints {
+		cmd1 := exec.Command("flutter", "pub", "remove", FlutterLints)
+		cmd1.Dir = projectPath
+		executeCommand(cmd1)
+
+		cmd2 := exec.Command("flutter", "pub", "add", "dev:"+lintPackage)
+		cmd2.Dir = projectPath
+		executeCommand(cmd2)
+	}
+
+	createFile(filepath.Join(projectPath, "analysis_options.yaml"), analysisOptions)
+}
+
+// analyzeProject runs flutter analyze in the app and the packages of the
+// workspace once the lint preset is applied. Every built-in template is
+// written to pass the presets above, an issue is reported as an error.
+func analyzeProject(projectPath string, packages []workspacePackage, opts projectOptions) error {
+	if !hasAddOn(opts, LintsAddOn) {
+		return nil
+	}
+	for _, dir := range append([]string{projectPath}, packagePaths(packages)...) {
+		cmd := exec.Command("flutter", "analyze")
+		cmd.Dir = dir
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("flutter analyze reported issues in %s under the %s lint preset: %w", dir, opts.LintPreset, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigureAnalyzerWithoutLintsAddOn(t *testing.T) {
+	log := useFakeFlutter(t)
+
+	project := t.TempDir()
+	pubspec := "name: demo\n\ndev_dependencies:\n  flutter_lints: ^5.0.0\n"
+	analysisOptions := "include: package:flutter_lints/flutter.yaml\n"
+	writeTestFiles(t, project, map[string]string{
+		"pubspec.yaml":          pubspec,
+		"analysis_options.yaml": analysisOptions,
+	})
+
+	opts := projectOptions{StateManager: RiverpodGenerator, LintPreset: VeryGoodAnalysis}
+	configureAnalyzer(project, nil, opts)
+
+	if got := readTestFile(t, filepath.Join(project, "analysis_options.yaml")); got != analysisOptions {
+		t.Errorf("analysis_options.yaml changed:\n%s", got)
+	}
+	if got := readTestFile(t, filepath.Join(project, "pubspec.yaml")); got != pubspec {
+		t.Errorf("pubspec.yaml changed:\n%s", got)
+	}
+	if commands, err := os.ReadFile(log); err == nil {
+		t.Errorf("no command should run, ran:\n%s", commands)
+	}
+	if plugins := analyzerPlugins(opts); len(plugins) > 0 {
+		t.Errorf("analyzer plugins without analysis_options.yaml: %v", plugins)
+	}
+
+	opts.Pattern, opts.ProjectName = patterns[0], "demo"
+	files, err := plannedFiles(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if file == "analysis_options.yaml" {
+			t.Error("analysis_options.yaml is listed without the Lints add-on")
+		}
+	}
+}
+
+func TestConfigureAnalyzerWithLintsAddOn(t *testing.T) {
+	log := useFakeFlutter(t)
+
+	project := t.TempDir()
+	writeTestFiles(t, project, map[string]string{
+		"pubspec.yaml":          "name: demo\n",
+		"analysis_options.yaml": "include: package:flutter_lints/flutter.yaml\n",
+	})
+
+	opts := projectOptions{StateManager: RiverpodGenerator, LintPreset: VeryGoodAnalysis, AddOns: []string{LintsAddOn}}
+	configureAnalyzer(project, nil, opts)
+
+	got := readTestFile(t, filepath.Join(project, "analysis_options.yaml"))
+	for _, want := range []string{"include: package:very_good_analysis/", "    - custom_lint\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("analysis_options.yaml is missing %q:\n%s", want, got)
+		}
+	}
+	commands := readTestFile(t, log)
+	for _, want := range []string{"flutter pub remove flutter_lints", "flutter pub add dev:very_good_analysis"} {
+		if !strings.Contains(commands, want) {
+			t.Errorf("%q was not run:\n%s", want, commands)
+		}
+	}
+}
+
+func TestAnalyzeProjectFailsOnIssues(t *testing.T) {
+	log := useFakeFlutter(t)
+	script := strings.Replace(fakeFlutterScript, "exit 0\n", "[ \"$1\" = analyze ] && exit 1\nexit 0\n", 1)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(log), "flutter"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	project := t.TempDir()
+	opts := projectOptions{LintPreset: VeryGoodAnalysis}
+	if err := analyzeProject(project, nil, opts); err != nil {
+		t.Errorf("the analyzer should not run without the Lints add-on: %v", err)
+	}
+	opts.AddOns = []string{LintsAddOn}
+	if err := analyzeProject(project, nil, opts); err == nil {
+		t.Error("the issues of the analyzer should stop the generation")
+	}
+	if commands := readTestFile(t, log); strings.Count(commands, "flutter analyze") != 1 {
+		t.Errorf("flutter analyze should run once:\n%s", commands)
+	}
+}
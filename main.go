@@ -1,1009 +1,277 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
 )
 
-const (
-	BLoC              string = "BLoC (Business Logic Component)"
-	Provider          string = "Provider"
-	Redux             string = "Redux"
-	ScopedModel       string = "Scoped Model"
-	Mvvm              string = "MVVM (Model-View-ViewModel)"
-	Mvc               string = "MVC (Model-View-Controller)"
-	Cubit             string = "Cubit"
-	Riverpod          string = "Riverpod"
-	GetX              string = "GetX"
-	MobX              string = "MobX"
-	StatesRebuilder   string = "States Rebuilder"
-	CleanArchitecture string = "Clean Architecture"
-)
+// projectOptions holds every answer needed to initialize a project.
+type projectOptions struct {
+	Pattern      string
+	StateManager string
+	Freezed      bool
+	ProjectName  string
+	Path         string
+	Monorepo     bool
+	// ExistingPolicy tells what to do when the project directory exists
+	ExistingPolicy string
+	AddOns         []string
+	LintPreset     string
+	LintFile       string
+	CIProviders    []string
+
+	GitInit          bool
+	GitBranch        string
+	GitCommitMessage string
+}
 
 func main() {
-	var architecture string
-
-	// List of architectures
-	architectures := []string{
-		BLoC,
-		Provider,
-		Redux,
-		ScopedModel,
-		Mvvm,
-		Mvc,
-		Cubit,
-		Riverpod,
-		GetX,
-		MobX,
-		StatesRebuilder,
-		CleanArchitecture,
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "model":
+			modelCommand(os.Args[2:])
+			return
+		case "api":
+			apiCommand(os.Args[2:])
+			return
+		case "add":
+			addCommand(os.Args[2:])
+			return
+		case "upgrade":
+			upgradeCommand(os.Args[2:])
+			return
+		case "diff":
+			diffCommand(os.Args[2:])
+			return
+		case "lint-arch":
+			lintArchCommand(os.Args[2:])
+			return
+		case "graph":
+			graphCommand(os.Args[2:])
+			return
+		case "migrate":
+			migrateCommand(os.Args[2:])
+			return
+		case "preset":
+			presetCommand(os.Args[2:])
+			return
+		}
 	}
 
-	// Prompt the user to select an architecture
-	prompt := &survey.Select{
-		Message: "Choose the architecture you want to use for your Flutter project:",
-		Options: architectures,
+	monorepo := flag.Bool("monorepo", false, "create a melos workspace with the app under apps/ and shared packages under packages/")
+	version := flag.Bool("version", false, "print the version and exit")
+	existing := flag.String("existing", "", "when the project directory exists: abort, overwrite (with a backup), merge (only add new files) or prompt (for each file)")
+	presetName := flag.String("preset", "", "use the answers of a saved preset, see flutter-arch preset list")
+	flag.Parse()
+	if *version {
+		fmt.Println("flutter-arch", toolVersion)
+		return
 	}
-	survey.AskOne(prompt, &architecture)
 
-	var projectName string
-	// Prompt the user to enter the project name
-	promptInput := &survey.Input{
-		Message: "Enter the project name:",
+	opts := defaultProjectOptions()
+	opts.Monorepo = *monorepo
+	if *existing != "" {
+		policy, ok := existingPolicyFlags[*existing]
+		if !ok {
+			fmt.Printf("Unknown --existing policy %s, use abort, overwrite, merge or prompt.\n", *existing)
+			return
+		}
+		opts.ExistingPolicy = policy
 	}
-	survey.AskOne(promptInput, &projectName)
-
-	var path string
-	// Prompt the user to enter the path
-	promptInput = &survey.Input{
-		Message: "Enter the path to initialize the project (press Enter for current directory):",
+	if *presetName != "" {
+		p, err := loadPreset(*presetName)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		applyPreset(&opts, p)
 	}
-	survey.AskOne(promptInput, &path)
 
-	path = strings.TrimSpace(path)
-	if path == "" {
-		path = "."
+	// Ask the questions of the wizard until the user confirms the summary
+	if err := runWizard(&opts, *presetName != ""); err != nil {
+		if err == terminal.InterruptErr || err == errWizardCancelled {
+			fmt.Println("Cancelled, nothing was created.")
+			return
+		}
+		fmt.Println("Error:", err)
+		return
 	}
 
 	// Create the Flutter project
-	initializeProject(architecture, projectName, path)
+	if err := initializeProject(opts); err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
 }
 
-func initializeProject(architecture, projectName, path string) {
+// defaultProjectOptions returns the answers the wizard suggests first.
+func defaultProjectOptions() projectOptions {
+	return projectOptions{
+		AddOns:           []string{LintsAddOn},
+		LintPreset:       FlutterLints,
+		GitInit:          true,
+		GitBranch:        defaultGitBranch,
+		GitCommitMessage: defaultGitCommitMessage,
+	}
+}
+
+// initializeProject generates the project of opts. It returns an error when
+// the generation stops, the project may then be partly written.
+func initializeProject(opts projectOptions) error {
+	architecture := architectureName(opts.Pattern, opts.StateManager)
+	projectName, path := opts.ProjectName, opts.Path
 	fmt.Printf("Initializing project %s using %s architecture in %s...\n", projectName, architecture, path)
 
+	projectPath := filepath.Join(path, projectName)
+	rootPath, createPath := projectPath, path
+
+	// Back up the directory when it exists, flutter create would update it
+	// and the templates replace its files
+	var existing *existingTarget
+	if _, err := os.Stat(rootPath); err == nil {
+		switch opts.ExistingPolicy {
+		case OverwriteExisting, MergeExisting, PromptExisting:
+			existing, err = backupExisting(rootPath, opts.ExistingPolicy)
+			if err != nil {
+				return fmt.Errorf("backing up the existing directory: %w", err)
+			}
+			// Settle the policy before anything is generated
+			if err := existing.prepare(opts); err != nil {
+				return err
+			}
+		default:
+			fmt.Printf("%s already exists, nothing was created. Pass --existing to overwrite or merge it.\n", rootPath)
+			return nil
+		}
+	}
+
+	// In monorepo mode the app lives under apps/ of a melos workspace
+	if opts.Monorepo {
+		rootPath, createPath = monorepoPaths(opts)
+		addMelosWorkspace(rootPath, opts)
+		projectPath = filepath.Join(createPath, projectName)
+	}
+
 	// Create the Flutter project
 	cmd := exec.Command("flutter", "create", projectName)
-	cmd.Dir = path
+	cmd.Dir = createPath
 	executeCommand(cmd)
 
-	projectPath := filepath.Join(path, projectName)
+	// Remember the files of flutter create, to tell the ones the tool changes
+	created, err := hashFiles(rootPath, projectPath)
+	if err != nil {
+		fmt.Println("Error hashing project files:", err)
+	}
 
 	// Add architecture-specific packages and example classes
-	switch architecture {
-	case BLoC:
-		addBlocArchitecture(projectPath)
-	case Provider:
-		addProviderArchitecture(projectPath)
-	case Redux:
-		addReduxArchitecture(projectPath)
-	case ScopedModel:
-		addScopedModelArchitecture(projectPath)
-	case Mvvm:
-		addMvvmArchitecture(projectPath)
-	case Mvc:
-		addMvcArchitecture(projectPath)
-	case Cubit:
-		addCubitArchitecture(projectPath)
-	case Riverpod:
-		addRiverpodArchitecture(projectPath)
-	case GetX:
-		addGetXArchitecture(projectPath)
-	case MobX:
-		addMobXArchitecture(projectPath)
-	case StatesRebuilder:
-		addStatesRebuilderArchitecture(projectPath)
-	case CleanArchitecture:
-		addCleanArchitecture(projectPath)
-	default:
+	if _, ok := stateManagerSpecs[opts.StateManager]; !ok {
 		fmt.Printf("Architecture %s is not supported yet.\n", architecture)
-		return
+		return nil
 	}
+	addArchitecture(projectPath, opts)
 
-	fmt.Printf("Project %s initialized successfully with %s architecture in %s\n", projectName, architecture, path)
-}
-
-func addBlocArchitecture(projectPath string) {
-	//Add necessary packages for BLoC
-	cmd := exec.Command("flutter", "pub", "add", "flutter_bloc", "bloc")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:flutter_bloc/flutter_bloc.dart';
-import 'bloc/counter_bloc.dart';
-
-void main() {
-  runApp(const MyApp());
-}
-
-class MyApp extends StatelessWidget {
-  const MyApp({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    return MaterialApp(
-      home: BlocProvider(
-        create: (context) => CounterBloc(),
-        child: const MyHomePage(),
-      ),
-    );
-  }
-}
-
-class MyHomePage extends StatelessWidget {
-  const MyHomePage({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    final counterBloc = BlocProvider.of<CounterBloc>(context);
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter BLoC Example"),
-      ),
-      body: Center(
-        child: BlocBuilder<CounterBloc, int>(
-          builder: (context, count) {
-            return Text("$count");
-          },
-        ),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: () {
-          counterBloc.add(CounterEvent.increment);
-        },
-        child: const Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterBlocContent := `
-import 'package:bloc/bloc.dart';
-
-enum CounterEvent { increment }
-
-class CounterBloc extends Bloc<CounterEvent, int> {
-  CounterBloc() : super(0);
-
-  Stream<int> mapEventToState(CounterEvent event) async* {
-    switch (event) {
-      case CounterEvent.increment:
-        yield state + 1;
-        break;
-    }
-  }
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "bloc"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "bloc", "counter_bloc.dart"), counterBlocContent)
-}
-
-func addProviderArchitecture(projectPath string) {
-	// Add necessary packages for Provider
-	cmd := exec.Command("flutter", "pub", "add", "provider")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:provider/provider.dart';
-import 'provider/counter_provider.dart';
-
-void main() {
-  runApp(MyApp());
-}
-
-class MyApp extends StatelessWidget {
-  @override
-  Widget build(BuildContext context) {
-    return MultiProvider(
-      providers: [
-        ChangeNotifierProvider(create: (_) => CounterProvider()),
-      ],
-      child: MaterialApp(
-        home: MyHomePage(),
-      ),
-    );
-  }
-}
-
-class MyHomePage extends StatelessWidget {
-  @override
-  Widget build(BuildContext context) {
-    final counterProvider = Provider.of<CounterProvider>(context);
-    return Scaffold(
-      appBar: AppBar(
-        title: Text("Flutter Provider Example"),
-      ),
-      body: Center(
-        child: Text("${counterProvider.count}"),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: () {
-          counterProvider.increment();
-        },
-        child: Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterProviderContent := `
-import 'package:flutter/material.dart';
-
-class CounterProvider with ChangeNotifier {
-  int _count = 0;
-
-  int get count => _count;
-
-  void increment() {
-    _count++;
-    notifyListeners();
-  }
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "provider"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "provider", "counter_provider.dart"), counterProviderContent)
-}
-
-func addReduxArchitecture(projectPath string) {
-	// Add necessary packages for Redux
-	cmd := exec.Command("flutter", "pub", "add", "redux", "flutter_redux")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:flutter_redux/flutter_redux.dart';
-import 'package:redux/redux.dart';
-import 'redux/counter_reducer.dart';
-
-void main() {
-  runApp(const MyApp());
-}
-
-class MyApp extends StatelessWidget {
-
-  const MyApp({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    final store = Store<int>(counterReducer, initialState: 0);
-    return StoreProvider<int>(
-      store: store,
-      child: const MaterialApp(
-        home: MyHomePage(),
-      ),
-    );
-  }
-}
-
-class MyHomePage extends StatelessWidget {
-
-  const MyHomePage({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter Redux Example"),
-      ),
-      body: Center(
-        child: StoreConnector<int, String>(
-          converter: (store) => store.state.toString(),
-          builder: (context, count) {
-            return Text(count);
-          },
-        ),
-      ),
-      floatingActionButton: StoreConnector<int, VoidCallback>(
-        converter: (store) {
-          return () => store.dispatch(CounterAction.increment);
-        },
-        builder: (context, callback) {
-          return FloatingActionButton(
-            onPressed: callback,
-            child: const Icon(Icons.add),
-          );
-        },
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterReducerContent := `
-enum CounterAction { increment }
-
-int counterReducer(int state, dynamic action) {
-  if (action == CounterAction.increment) {
-    return state + 1;
-  }
-  return state;
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "redux"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "redux", "counter_reducer.dart"), counterReducerContent)
-}
-
-func addScopedModelArchitecture(projectPath string) {
-	// Add necessary packages for ScopedModel
-	cmd := exec.Command("flutter", "pub", "add", "scoped_model")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:scoped_model/scoped_model.dart';
-import 'scoped_model/counter_model.dart';
-
-void main() {
-  runApp(const MyApp());
-}
-
-class MyApp extends StatelessWidget {
-  const MyApp({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    return ScopedModel<CounterModel>(
-      model: CounterModel(),
-      child: const MaterialApp(
-        home: MyHomePage(),
-      ),
-    );
-  }
-}
-
-class MyHomePage extends StatelessWidget {
-  const MyHomePage({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter ScopedModel Example"),
-      ),
-      body: Center(
-        child: ScopedModelDescendant<CounterModel>(
-          builder: (context, child, model) {
-            return Text("${model.count}");
-          },
-        ),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: () {
-          ScopedModel.of<CounterModel>(context).increment();
-        },
-        child: const Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterModelContent := `
-import 'package:scoped_model/scoped_model.dart';
-
-class CounterModel extends Model {
-  int _count = 0;
-
-  int get count => _count;
-
-  void increment() {
-    _count++;
-    notifyListeners();
-  }
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "scoped_model"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "scoped_model", "counter_model.dart"), counterModelContent)
-}
-
-func addMvvmArchitecture(projectPath string) {
-	// Add necessary packages for Provider
-	cmd := exec.Command("flutter", "pub", "add", "provider")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:provider/provider.dart';
-import 'viewmodel/counter_viewmodel.dart';
-
-void main() {
-  runApp(const MyApp());
-}
-
-class MyApp extends StatelessWidget {
-  const MyApp({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    return MultiProvider(
-      providers: [
-        ChangeNotifierProvider(create: (_) => CounterViewModel()),
-      ],
-      child: const MaterialApp(
-        home: MyHomePage(),
-      ),
-    );
-  }
-}
-
-class MyHomePage extends StatelessWidget {
-  const MyHomePage({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    final counterViewModel = Provider.of<CounterViewModel>(context);
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter MVVM Example"),
-      ),
-      body: Center(
-        child: Text("${counterViewModel.count}"),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: () {
-          counterViewModel.increment();
-        },
-        child: const Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterViewModelContent := `
-import 'package:flutter/material.dart';
-
-class CounterViewModel extends ChangeNotifier {
-  int _count = 0;
-
-  int get count => _count;
-
-  void increment() {
-    _count++;
-    notifyListeners();
-  }
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "viewmodel"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "viewmodel", "counter_viewmodel.dart"), counterViewModelContent)
-}
-
-func addMvcArchitecture(projectPath string) {
-	// Add necessary packages for MVC
-	cmd := exec.Command("flutter", "pub", "add", "mvc_pattern")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:mvc_pattern/mvc_pattern.dart';
-import 'controller/counter_controller.dart';
-
-void main() {
-  runApp(MyApp());
-}
-
-class MyApp extends StatelessWidget {
-  @override
-  Widget build(BuildContext context) {
-    return MaterialApp(
-      home: MyHomePage(),
-    );
-  }
-}
-
-class MyHomePage extends StatefulWidget {
-  @override
-  State createState() => _MyHomePageState();
-}
-
-class _MyHomePageState extends StateMVC<MyHomePage> {
-  _MyHomePageState() : super(CounterController()) {
-    con = controller as CounterController;
-  }
-
-  late CounterController con;
-
-  @override
-  Widget build(BuildContext context) {
-    return Scaffold(
-      appBar: AppBar(
-        title: Text("Flutter MVC Example"),
-      ),
-      body: Center(
-        child: Text("${con.count}"),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: con.increment,
-        child: Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterControllerContent := `
-import 'package:mvc_pattern/mvc_pattern.dart';
-
-class CounterController extends ControllerMVC {
-  int _count = 0;
-
-  int get count => _count;
-
-  void increment() {
-    setState(() {
-      _count++;
-    });
-  }
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "controller"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "controller", "counter_controller.dart"), counterControllerContent)
-}
-
-func addCubitArchitecture(projectPath string) {
-	// Add necessary packages for Cubit
-	cmd := exec.Command("flutter", "pub", "add", "flutter_bloc", "bloc")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:flutter_bloc/flutter_bloc.dart';
-import 'cubit/counter_cubit.dart';
-
-void main() {
-  runApp(const MyApp());
-}
-
-class MyApp extends StatelessWidget {
-  const MyApp({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    return MaterialApp(
-      home: BlocProvider(
-        create: (context) => CounterCubit(),
-        child: const MyHomePage(),
-      ),
-    );
-  }
-}
-
-class MyHomePage extends StatelessWidget {
-  const MyHomePage({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    final counterCubit = BlocProvider.of<CounterCubit>(context);
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter Cubit Example"),
-      ),
-      body: Center(
-        child: BlocBuilder<CounterCubit, int>(
-          builder: (context, count) {
-            return Text("$count");
-          },
-        ),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: () {
-          counterCubit.increment();
-        },
-        child: const Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterCubitContent := `
-import 'package:bloc/bloc.dart';
-
-class CounterCubit extends Cubit<int> {
-  CounterCubit() : super(0);
-
-  void increment() => emit(state + 1);
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "cubit"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "cubit", "counter_cubit.dart"), counterCubitContent)
-}
-
-func addRiverpodArchitecture(projectPath string) {
-	// Add necessary packages for Riverpod
-	cmd := exec.Command("flutter", "pub", "add", "flutter_riverpod")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:flutter_riverpod/flutter_riverpod.dart';
-
-void main() {
-  runApp(const ProviderScope(child: MyApp()));
-}
-
-class MyApp extends StatelessWidget {
-  const MyApp({super.key});
-
-  @override
-  Widget build(BuildContext context) {
-    return const MaterialApp(
-      home: MyHomePage(),
-    );
-  }
-}
-
-final counterProvider = StateProvider<int>((ref) {
-  return 0;
-});
-
-class MyHomePage extends ConsumerWidget {
-  const MyHomePage({super.key});
-
-  @override
-  Widget build(BuildContext context, WidgetRef ref) {
-    final count = ref.watch(counterProvider);
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter Riverpod Example"),
-      ),
-      body: Center(
-        child: Text("$count"),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: () {
-          ref.read(counterProvider.notifier).state++;
-        },
-        child: const Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-}
+	// Split the architecture layers across the workspace packages
+	var packages []workspacePackage
+	if opts.Monorepo {
+		packages = splitMonorepoPackages(rootPath, projectPath)
+	}
 
-func addGetXArchitecture(projectPath string) {
-	// Add necessary packages for GetX
-	cmd := exec.Command("flutter", "pub", "add", "get")
-	cmd.Dir = projectPath
+	// Format the generated code so it passes dart format checks
+	cmd = exec.Command("dart", "format", ".")
+	cmd.Dir = rootPath
 	executeCommand(cmd)
 
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:get/get.dart';
-import 'controller/counter_controller.dart';
-
-void main() {
-  runApp(const MyApp());
-}
+	// Configure the analyzer for the lint preset
+	configureAnalyzer(projectPath, packages, opts)
 
-class MyApp extends StatelessWidget {
-  const MyApp({super.key});
-  @override
-  Widget build(BuildContext context) {
-    return const GetMaterialApp(
-      home: MyHomePage(),
-    );
-  }
-}
+	// Add continuous integration pipelines
+	addCIPipelines(rootPath, opts)
 
-class MyHomePage extends StatelessWidget {
-  const MyHomePage({super.key});
-  @override
-  Widget build(BuildContext context) {
-    final CounterController counterController = Get.put(CounterController());
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter GetX Example"),
-      ),
-      body: Center(
-        child: Obx(() {
-          return Text("${counterController.count}");
-        }),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: counterController.increment,
-        child: const Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterControllerContent := `
-import 'package:get/get.dart';
-
-class CounterController extends GetxController {
-  var count = 0.obs;
-
-  void increment() => count++;
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "controller"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "controller", "counter_controller.dart"), counterControllerContent)
-}
-
-func addMobXArchitecture(projectPath string) {
-	// Add necessary packages for MobX
-	cmd1 := exec.Command("flutter", "pub", "add", "flutter_mobx", "mobx", "provider")
-	cmd1.Dir = projectPath
-	executeCommand(cmd1)
-
-	cmd2 := exec.Command("flutter", "pub", "add", "dev:build_runner", "build")
-	cmd2.Dir = projectPath
-	executeCommand(cmd2)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:flutter_mobx/flutter_mobx.dart';
-import 'package:provider/provider.dart';
-import 'store/counter_store.dart';
-
-void main() {
-  runApp(MyApp());
-}
-
-class MyApp extends StatelessWidget {
-  @override
-  Widget build(BuildContext context) {
-    return MultiProvider(
-      providers: [
-        Provider<CounterStore>(create: (_) => CounterStore()),
-      ],
-      child: MaterialApp(
-        home: MyHomePage(),
-      ),
-    );
-  }
-}
-
-class MyHomePage extends StatelessWidget {
-  @override
-  Widget build(BuildContext context) {
-    final counterStore = Provider.of<CounterStore>(context);
-    return Scaffold(
-      appBar: AppBar(
-        title: Text("Flutter MobX Example"),
-      ),
-      body: Center(
-        child: Observer(
-          builder: (_) => Text("${counterStore.count}"),
-        ),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: counterStore.increment,
-        child: Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	counterStoreContent := `
-import 'package:mobx/mobx.dart';
-
-part 'counter_store.g.dart';
-
-class CounterStore = _CounterStore with _$CounterStore;
+	// Keep the existing files the policy protects, the manifest then leaves
+	// them out like the files of flutter create
+	if existing != nil {
+		for _, file := range existing.resolve() {
+			content, _ := os.ReadFile(filepath.Join(rootPath, filepath.FromSlash(file)))
+			created[file] = hashContent(content)
+		}
+	}
 
-abstract class _CounterStore with Store {
-  @observable
-  int count = 0;
+	// Record how the project was generated, for the commands updating it
+	writeManifest(rootPath, opts, created)
 
-  @action
-  void increment() {
-    count++;
-  }
-}
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "store"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "store", "counter_store.dart"), counterStoreContent)
-	createFile(filepath.Join(projectPath, "lib", "store", "counter_store.g.dart"), "")
-}
-
-func addStatesRebuilderArchitecture(projectPath string) {
-	// Add necessary packages for States Rebuilder
-	cmd := exec.Command("flutter", "pub", "add", "states_rebuilder")
-	cmd.Dir = projectPath
-	executeCommand(cmd)
+	// The templates are written to pass the lint preset, stop before the
+	// commit when the analyzer reports anything
+	if err := analyzeProject(projectPath, packages, opts); err != nil {
+		return err
+	}
 
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:states_rebuilder/states_rebuilder.dart';
+	// Commit the generated project, last so that every file is included
+	if opts.GitInit {
+		initializeGitRepository(rootPath, opts)
+	}
 
-void main() {
-  runApp(const MyApp());
+	fmt.Printf("Project %s initialized successfully with %s architecture in %s\n", projectName, architecture, path)
+	return nil
 }
 
-class MyApp extends StatelessWidget {
-  const MyApp({super.key});
-  @override
-  Widget build(BuildContext context) {
-    return MaterialApp(
-      home: MyHomePage(),
-    );
-  }
+// configureAnalyzer applies the lint preset to the app and the packages of
+// the workspace. Without the Lints add-on, the analysis_options.yaml and
+// flutter_lints of flutter create are left as they are.
+func configureAnalyzer(projectPath string, packages []workspacePackage, opts projectOptions) {
+	if !hasAddOn(opts, LintsAddOn) {
+		return
+	}
+	addLintPreset(projectPath, opts.LintPreset, opts.LintFile, generatedFiles(opts), analyzerPlugins(opts))
+	for _, dir := range packagePaths(packages) {
+		addLintPreset(dir, opts.LintPreset, opts.LintFile, generatedFiles(opts), nil)
+	}
 }
 
-class MyHomePage extends StatelessWidget {
-  final counterRM = RM.inject(() => 0);
-
-  @override
-  Widget build(BuildContext context) {
-    return Scaffold(
-      appBar: AppBar(
-        title: const Text("Flutter States Rebuilder Example"),
-      ),
-      body: Center(
-        child: OnBuilder(
-          listenTo: counterRM,
-          builder: () => Text("${counterRM.state}"),
-        ),
-      ),
-      floatingActionButton: FloatingActionButton(
-        onPressed: () {
-          counterRM.state++;
-        },
-        child: const Icon(Icons.add),
-      ),
-    );
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
+// usesCodegen reports whether the generated project relies on build_runner.
+func usesCodegen(opts projectOptions) bool {
+	return opts.StateManager == MobX || opts.StateManager == Stacked || opts.StateManager == RiverpodGenerator || opts.Freezed
 }
 
-func addCleanArchitecture(projectPath string) {
-	// Add necessary packages for CleanArchitecture
-	cmd1 := exec.Command("flutter", "pub", "add", "get_it")
-	cmd1.Dir = projectPath
-	executeCommand(cmd1)
-
-	cmd2 := exec.Command("flutter", "pub", "add", "provider")
-	cmd2.Dir = projectPath
-	executeCommand(cmd2)
-
-	// Create example classes
-	mainContent := `
-import 'package:flutter/material.dart';
-import 'package:provider/provider.dart';
-import 'injection_container.dart' as di;
-import 'features/counter/presentation/pages/counter_page.dart';
-	
-void main() {
-	di.init();
-	runApp(const MyApp());
-}
-	
-class MyApp extends StatelessWidget {
-const MyApp({super.key});
-	
-@override
-Widget build(BuildContext context) {
-	return MultiProvider(
-		providers: di.providers,
-		  child: const MaterialApp(
-			home: CounterPage(),
-		  ),
-		);
+// generatedFiles returns the patterns of the files build_runner writes in
+// the generated project.
+func generatedFiles(opts projectOptions) []string {
+	files := []string{"*.g.dart", "*.freezed.dart"}
+	if opts.StateManager == Stacked {
+		files = append(files, stackedGeneratedFiles...)
 	}
-}	
-`
-	createFile(filepath.Join(projectPath, "lib", "main.dart"), mainContent)
-
-	injectionContainerContent := `
-import 'package:get_it/get_it.dart';
-import 'package:provider/provider.dart';
-import 'package:provider/single_child_widget.dart';
-import 'features/counter/presentation/provider/counter_provider.dart';
-	
-final sl = GetIt.instance;
-	
-void init() {
-	sl.registerFactory(() => CounterProvider());
-	
-	providers = [
-		ChangeNotifierProvider(create: (_) => sl<CounterProvider>()),
-	];
+	return files
 }
-	
-List<SingleChildWidget> providers = [];	
-`
-	os.MkdirAll(filepath.Join(projectPath, "lib", "features", "counter", "presentation", "pages"), 0755)
-	os.MkdirAll(filepath.Join(projectPath, "lib", "features", "counter", "presentation", "provider"), 0755)
-	createFile(filepath.Join(projectPath, "lib", "injection_container.dart"), injectionContainerContent)
 
-	counterPageContent := `
-import 'package:flutter/material.dart';
-import 'package:provider/provider.dart';
-import '../provider/counter_provider.dart';
-	
-class CounterPage extends StatelessWidget {
-	const CounterPage({super.key});
-	
-@override
-Widget build(BuildContext context) {
-	final counterProvider = Provider.of<CounterProvider>(context);
-	return Scaffold(
-		appBar: AppBar(
-			title: const Text("Flutter Clean Architecture Example"),
-		),
-		body: Center(
-			child: Text("${counterProvider.count}"),
-		),
-		floatingActionButton: FloatingActionButton(
-			onPressed: counterProvider.increment,
-			child: const Icon(Icons.add),
-		  ),
-		);
+// analyzerPlugins returns the analyzer plugins of the app, the packages of a
+// monorepo do not depend on them. They are enabled by the analysis_options.yaml
+// of the lint preset.
+func analyzerPlugins(opts projectOptions) []string {
+	if opts.StateManager == RiverpodGenerator && hasAddOn(opts, LintsAddOn) {
+		// custom_lint runs the rules of riverpod_lint
+		return []string{"custom_lint"}
 	}
-}	
-`
-	createFile(filepath.Join(projectPath, "lib", "features", "counter", "presentation", "pages", "counter_page.dart"), counterPageContent)
-
-	counterProviderContent := `
-import 'package:flutter/material.dart';
-
-class CounterProvider with ChangeNotifier {
-  int _count = 0;
+	return nil
+}
 
-  int get count => _count;
+// stackedGeneratedFiles are the outputs of stacked_generator, named after the
+// file declaring the app.
+var stackedGeneratedFiles = []string{"*.locator.dart", "*.router.dart"}
 
-  void increment() {
-    _count++;
-    notifyListeners();
-  }
-}
-`
-	createFile(filepath.Join(projectPath, "lib", "features", "counter", "presentation", "provider", "counter_provider.dart"), counterProviderContent)
+// isGeneratedDart reports whether a Dart file is written by build_runner.
+func isGeneratedDart(filePath string) bool {
+	for _, pattern := range append([]string{"*.g.dart", "*.freezed.dart"}, stackedGeneratedFiles...) {
+		if strings.HasSuffix(filePath, strings.TrimPrefix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
 }
 
 func executeCommand(cmd *exec.Cmd) {
@@ -1027,3 +295,74 @@ func createFile(filePath, content string) {
 		fmt.Println("Error writing to file:", err)
 	}
 }
+
+// renderDart fills the {{package}} placeholder of a Dart template with the
+// package name of the project at projectPath and sorts the import directives
+// (dart:, then package:, then relative) so that the generated file satisfies
+// directives_ordering whatever the project is called.
+func renderDart(projectPath, content string) string {
+	content = strings.ReplaceAll(content, "{{package}}", filepath.Base(projectPath))
+	return sortDartImports(strings.TrimLeft(content, "\n"))
+}
+
+// sortDartImports sorts and deduplicates the import directives at the top of
+// a Dart file.
+func sortDartImports(content string) string {
+	lines := strings.Split(content, "\n")
+	var imports []string
+	seen := make(map[string]bool)
+	end := 0
+	for ; end < len(lines); end++ {
+		line := strings.TrimSpace(lines[end])
+		if strings.HasPrefix(line, "import ") {
+			// Templates may import a library twice, once per use
+			if !seen[line] {
+				imports = append(imports, line)
+			}
+			seen[line] = true
+		} else if line != "" {
+			break
+		}
+	}
+	if len(imports) == 0 {
+		return content
+	}
+
+	groups := make([][]string, 3)
+	for _, line := range imports {
+		uri := importURI(line)
+		switch {
+		case strings.HasPrefix(uri, "dart:"):
+			groups[0] = append(groups[0], line)
+		case strings.HasPrefix(uri, "package:"):
+			groups[1] = append(groups[1], line)
+		default:
+			groups[2] = append(groups[2], line)
+		}
+	}
+
+	var sorted []string
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return importURI(group[i]) < importURI(group[j]) })
+		sorted = append(sorted, group...)
+		sorted = append(sorted, "")
+	}
+
+	return strings.Join(append(sorted, lines[end:]...), "\n")
+}
+
+// importURI returns the quoted URI of a Dart import directive.
+func importURI(directive string) string {
+	start := strings.IndexAny(directive, `'"`)
+	if start < 0 {
+		return directive
+	}
+	end := strings.IndexByte(directive[start+1:], directive[start])
+	if end < 0 {
+		return directive[start+1:]
+	}
+	return directive[start+1 : start+1+end]
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeFlutterScript stands in for flutter and dart: create makes the package
+// skeleton, every other command succeeds without doing anything. The commands
+// are logged to $FAKE_FLUTTER_LOG.
+const fakeFlutterScript = `#!/bin/sh
+echo "$(basename "$0") $*" >> "$FAKE_FLUTTER_LOG"
+if [ "$1" = create ]; then
+  for a in "$@"; do case $a in -*|create) ;; *) name=$a;; esac; done
+  mkdir -p "$name/lib" "$name/test"
+  printf 'name: %s\n\ndependencies:\n  flutter:\n    sdk: flutter\n' "$name" > "$name/pubspec.yaml"
+fi
+exit 0
+`
+
+// useFakeFlutter puts fake flutter and dart commands first in PATH and
+// returns the file logging the commands run.
+func useFakeFlutter(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake flutter command is a shell script")
+	}
+	bin := t.TempDir()
+	for _, name := range []string{"flutter", "dart"} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(fakeFlutterScript), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	log := filepath.Join(bin, "commands.log")
+	t.Setenv("FAKE_FLUTTER_LOG", log)
+	return log
+}
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
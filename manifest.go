@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+)
+
+// toolVersion is the version of flutter-arch recorded in the manifests, set
+// at build time with -ldflags "-X main.toolVersion=<version>".
+var toolVersion = "1.0.0"
+
+// manifestFile is the name of the manifest written at the root of the
+// generated project.
+const manifestFile string = ".flutter-arch.json"
+
+// templateVersions are the versions of the template sets. Bump the version of
+// a set whenever one of its templates changes, so that the manifest tells
+// which templates a project was generated from.
+var templateVersions = map[string]int{
+	"sample":   1,
+	"add-ons":  1,
+	"lints":    1,
+	"ci":       1,
+	"monorepo": 1,
+}
+
+// manifestIgnoredDirs hold tool caches and build outputs, never generated by
+// the templates.
+var manifestIgnoredDirs = map[string]bool{
+	".git":       true,
+	".dart_tool": true,
+	".idea":      true,
+	"build":      true,
+	"Pods":       true,
+	"ephemeral":  true,
+}
+
+// manifestIgnoredFiles change on every pub get.
+var manifestIgnoredFiles = map[string]bool{
+	manifestFile:                    true,
+	"pubspec.lock":                  true,
+	".flutter-plugins":              true,
+	".flutter-plugins-dependencies": true,
+}
+
+// projectManifest records how a project was generated, for the commands that
+// later update it.
+type projectManifest struct {
+	Tool         string         `json:"tool"`
+	Version      string         `json:"version"`
+	GeneratedAt  string         `json:"generated_at"`
+	ProjectName  string         `json:"project_name"`
+	Architecture string         `json:"architecture"`
+	Options      preset         `json:"options"`
+	Templates    map[string]int `json:"templates"`
+	// Files maps the paths, relative to the manifest, of the files the tool
+	// created or changed to their SHA-256
+	Files map[string]string `json:"files"`
+	// Base holds the content of the text files among them, the common
+	// ancestor upgrade merges the user's changes and the new templates from
+	Base map[string]string `json:"base,omitempty"`
+}
+
+// hashFiles returns the SHA-256 of the files under root by slash-separated
+// path relative to base, leaving out caches, build outputs and the code
+// build_runner generates.
+func hashFiles(base, root string) (map[string]string, error) {
+	hashes := make(map[string]string)
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if manifestIgnoredDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := entry.Name()
+		if manifestIgnoredFiles[name] || isGeneratedDart(name) {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, filePath)
+		if err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(rel)] = hashContent(content)
+		return nil
+	})
+	return hashes, err
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeManifest records the options and the files the tool created or
+// changed since flutter create, whose files are given by created, in
+// .flutter-arch.json at rootPath.
+func writeManifest(rootPath string, opts projectOptions, created map[string]string) {
+	files, err := hashFiles(rootPath, rootPath)
+	if err != nil {
+		fmt.Println("Error hashing generated files:", err)
+		return
+	}
+	base := make(map[string]string)
+	for file, hash := range files {
+		if created[file] == hash {
+			delete(files, file)
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(rootPath, filepath.FromSlash(file)))
+		if err == nil && utf8.Valid(content) {
+			base[file] = string(content)
+		}
+	}
+
+	templates := map[string]int{"sample": templateVersions["sample"]}
+	if len(opts.AddOns) > 0 {
+		templates["add-ons"] = templateVersions["add-ons"]
+	}
+	if hasAddOn(opts, LintsAddOn) {
+		templates["lints"] = templateVersions["lints"]
+	}
+	if len(opts.CIProviders) > 0 {
+		templates["ci"] = templateVersions["ci"]
+	}
+	if opts.Monorepo {
+		templates["monorepo"] = templateVersions["monorepo"]
+	}
+
+	manifest := projectManifest{
+		Tool:         "flutter-arch",
+		Version:      toolVersion,
+		GeneratedAt:  time.Now().UTC().Format(time.RFC3339),
+		ProjectName:  opts.ProjectName,
+		Architecture: architectureName(opts.Pattern, opts.StateManager),
+		Options:      presetFromOptions(opts),
+		Templates:    templates,
+		Files:        files,
+		Base:         base,
+	}
+	if err := saveManifest(rootPath, manifest); err != nil {
+		fmt.Println("Error writing manifest:", err)
+	}
+}
+
+func saveManifest(rootPath string, manifest projectManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(rootPath, manifestFile), append(content, '\n'), 0644)
+}
+
+// readManifest reads the manifest of the project generated at rootPath.
+func readManifest(rootPath string) (projectManifest, error) {
+	var manifest projectManifest
+	content, err := os.ReadFile(filepath.Join(rootPath, manifestFile))
+	if err != nil {
+		return manifest, fmt.Errorf("%s has no %s, it was not generated by flutter-arch or is too old: %w", rootPath, manifestFile, err)
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("%s: %w", manifestFile, err)
+	}
+	return manifest, nil
+}
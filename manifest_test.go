@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestWriteManifestTemplates(t *testing.T) {
+	tests := []struct {
+		name   string
+		addOns []string
+		lints  bool
+	}{
+		{name: "without the Lints add-on", addOns: []string{TestsAddOn}},
+		{name: "with the Lints add-on", addOns: []string{TestsAddOn, LintsAddOn}, lints: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeTestFiles(t, root, map[string]string{"lib/main.dart": "void main() {}\n"})
+			opts := projectOptions{ProjectName: "demo", Pattern: patterns[0], StateManager: Provider, AddOns: tt.addOns, LintPreset: FlutterLints}
+			writeManifest(root, opts, map[string]string{})
+
+			manifest, err := readManifest(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := manifest.Templates["lints"]; ok != tt.lints {
+				t.Errorf("lints template recorded = %v, want %v: %v", ok, tt.lints, manifest.Templates)
+			}
+			if _, ok := manifest.Templates["add-ons"]; !ok {
+				t.Errorf("add-ons template missing: %v", manifest.Templates)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"strings"
+)
+
+// mergeResult is the outcome of a three-way merge.
+type mergeResult struct {
+	Content   string
+	Conflicts int
+}
+
+// splitLines splits text into lines keeping their line endings, so that
+// joining them gives the text back.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// matchLines returns, for each line of a, the index of the line of b it is
+// matched to in a longest common subsequence of the two, or -1.
+func matchLines(a, b []string) []int {
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// Common prefix and suffix are matched without the quadratic table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lengths[i][j] is the length of the LCS of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			matches[prefix+i] = prefix + j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
+
+// merge3 merges the changes from base to theirs into ours, line by line.
+// Chunks changed on both sides in different ways are kept with conflict
+// markers, in the diff3 style of git with the base in the middle.
+func merge3(base, ours, theirs, oursLabel, baseLabel, theirsLabel string) mergeResult {
+	o, a, b := splitLines(base), splitLines(ours), splitLines(theirs)
+	matchA, matchB := matchLines(o, a), matchLines(o, b)
+
+	var result mergeResult
+	var out strings.Builder
+	write := func(lines []string) {
+		for _, line := range lines {
+			out.WriteString(line)
+		}
+	}
+	marker := func(marker, label string) {
+		// Markers start on their own line even when the chunk misses its
+		// final newline
+		if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+			out.WriteString("\n")
+		}
+		if label != "" {
+			marker += " " + label
+		}
+		out.WriteString(marker + "\n")
+	}
+
+	oi, ai, bi := 0, 0, 0
+	for {
+		// Copy the lines unchanged on both sides
+		stable := 0
+		for oi+stable < len(o) && matchA[oi+stable] == ai+stable && matchB[oi+stable] == bi+stable {
+			stable++
+		}
+		if stable > 0 {
+			write(o[oi : oi+stable])
+			oi, ai, bi = oi+stable, ai+stable, bi+stable
+			continue
+		}
+
+		// The changed chunk ends at the next base line kept on both sides
+		next := oi
+		for next < len(o) && (matchA[next] == -1 || matchB[next] == -1) {
+			next++
+		}
+		endA, endB := len(a), len(b)
+		if next < len(o) {
+			endA, endB = matchA[next], matchB[next]
+		}
+		chunkO, chunkA, chunkB := o[oi:next], a[ai:endA], b[bi:endB]
+		if len(chunkO) == 0 && len(chunkA) == 0 && len(chunkB) == 0 {
+			break
+		}
+
+		switch {
+		case equalLines(chunkA, chunkO):
+			write(chunkB)
+		case equalLines(chunkB, chunkO), equalLines(chunkA, chunkB):
+			write(chunkA)
+		default:
+			result.Conflicts++
+			marker("<<<<<<<", oursLabel)
+			write(chunkA)
+			marker("|||||||", baseLabel)
+			write(chunkO)
+			marker("=======", "")
+			write(chunkB)
+			marker(">>>>>>>", theirsLabel)
+		}
+		oi, ai, bi = next, endA, endB
+	}
+
+	result.Content = out.String()
+	return result
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package main
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          int
+	}{
+		{
+			name:   "disjoint edits",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "a\nB\nc\nd\ne\n",
+			theirs: "a\nb\nc\nD\ne\n",
+			want:   "a\nB\nc\nD\ne\n",
+		},
+		{
+			name:   "identical edits",
+			base:   "a\nb\nc\n",
+			ours:   "a\nX\nc\nd\n",
+			theirs: "a\nX\nc\nd\n",
+			want:   "a\nX\nc\nd\n",
+		},
+		{
+			name:   "conflicting chunks",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "a\nb1\nc\nd1\ne\n",
+			theirs: "a\nb2\nc\nd2\ne\n",
+			want: "a\n" +
+				"<<<<<<< ours\nb1\n||||||| base\nb\n=======\nb2\n>>>>>>> theirs\n" +
+				"c\n" +
+				"<<<<<<< ours\nd1\n||||||| base\nd\n=======\nd2\n>>>>>>> theirs\n" +
+				"e\n",
+			conflicts: 2,
+		},
+		{
+			name:   "insertions at the start and the end",
+			base:   "a\nb\n",
+			ours:   "x\na\nb\n",
+			theirs: "a\nb\ny\n",
+			want:   "x\na\nb\ny\n",
+		},
+		{
+			name:   "missing trailing newline",
+			base:   "a\nb",
+			ours:   "a\nb",
+			theirs: "a\nc",
+			want:   "a\nc",
+		},
+		{
+			name:      "conflict without trailing newline",
+			base:      "a\nb",
+			ours:      "A\nb",
+			theirs:    "a\nb\n",
+			want:      "<<<<<<< ours\nA\nb\n||||||| base\na\nb\n=======\na\nb\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+		{
+			name:   "empty base with the same content",
+			base:   "",
+			ours:   "a\n",
+			theirs: "a\n",
+			want:   "a\n",
+		},
+		{
+			name:   "empty base added on one side",
+			base:   "",
+			ours:   "",
+			theirs: "b\n",
+			want:   "b\n",
+		},
+		{
+			name:      "empty base with different contents",
+			base:      "",
+			ours:      "a\n",
+			theirs:    "b\n",
+			want:      "<<<<<<< ours\na\n||||||| base\n=======\nb\n>>>>>>> theirs\n",
+			conflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := merge3(tt.base, tt.ours, tt.theirs, "ours", "base", "theirs")
+			if got.Content != tt.want || got.Conflicts != tt.conflicts {
+				t.Errorf("got %d conflicts:\n%s\nwant %d conflicts:\n%s", got.Conflicts, got.Content, tt.conflicts, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1641 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// migrateSources and migrateTargets map the values of the --from and --to
+// flags of migrate to the state managers.
+var migrateSources = map[string]string{
+	"provider":     Provider,
+	"scoped-model": ScopedModel,
+}
+
+var migrateTargets = map[string]string{
+	"riverpod": Riverpod,
+	"cubit":    Cubit,
+	// A Bloc needs an event per method, written by hand, the Cubit is where
+	// it starts from
+	"bloc": Cubit,
+}
+
+// migrationImports are the libraries the widgets of a state manager import.
+var migrationImports = map[string]string{
+	Provider:    "package:provider/provider.dart",
+	ScopedModel: "package:scoped_model/scoped_model.dart",
+	Riverpod:    "package:flutter_riverpod/flutter_riverpod.dart",
+	Cubit:       "package:flutter_bloc/flutter_bloc.dart",
+}
+
+// holderPatterns find the state holders of the source state managers.
+var holderPatterns = map[string]*regexp.Regexp{
+	Provider:    regexp.MustCompile(`(?m)^class\s+(\w+)\s+extends\s+(ChangeNotifier)\b[^{]*\{`),
+	ScopedModel: regexp.MustCompile(`(?m)^class\s+(\w+)\s+extends\s+(Model)\b[^{]*\{`),
+}
+
+// leftoverPatterns find the code of the source state managers the
+// migration did not rewrite.
+var leftoverPatterns = map[string]*regexp.Regexp{
+	Provider:    regexp.MustCompile(`\b(?:ChangeNotifierProxyProvider|ChangeNotifierProvider|MultiProvider|ListenableProvider|ProxyProvider\d*|ChangeNotifier|notifyListeners)\b|\b(?:Consumer\d*|Selector\d*|Provider)<|\bProvider\.(?:of|value)\b`),
+	ScopedModel: regexp.MustCompile(`\b(?:ScopedModelDescendant|ScopedModel|notifyListeners)\b|\bextends\s+Model\b`),
+}
+
+var (
+	// contextLookupPattern finds the provider lookups left when migrating
+	// to Riverpod, which has no BuildContext extensions
+	contextLookupPattern   = regexp.MustCompile(`\b\w+\.(?:watch|read|select)<(\w+)`)
+	riverpodUsePattern     = regexp.MustCompile(`\b(?:ConsumerWidget|ConsumerStatefulWidget|ConsumerState|ProviderScope|WidgetRef|NotifierProvider)\b|\bConsumer\(|\bref\.(?:watch|read)\(`)
+	blocUsePattern         = regexp.MustCompile(`\b(?:BlocProvider|MultiBlocProvider|BlocBuilder)\b`)
+	importDirectivePattern = regexp.MustCompile(`(?m)^import\s+['"]([^'"]+)['"](?:\s+as\s+(\w+))?[^;]*;`)
+	dartWordPattern        = regexp.MustCompile(`[A-Za-z_]\w*`)
+)
+
+// migrationNote is code the migration leaves to the user.
+type migrationNote struct {
+	File string
+	Line int
+	// Snippet is the line the note is about, to find it again once the file
+	// is rewritten
+	Snippet string
+	Message string
+}
+
+// stateHolder is a ChangeNotifier or Model class the migration rewrites.
+type stateHolder struct {
+	Name string
+	File string
+	// The mutable field keeping the state, its initial value and the getter
+	// exposing it
+	Field     string
+	StateType string
+	Initial   string
+	Getter    string
+	// Getters and Methods are the other members consumers may use
+	Getters map[string]bool
+	Methods map[string]bool
+	// NeedsArgs is set when the constructor takes parameters
+	NeedsArgs bool
+	// Create is the expression creating the holder, taken from where it is
+	// registered, and CreateImports the import directives it needs
+	Create        string
+	CreateImports []string
+	// Provider names the Riverpod provider of the holder
+	Provider string
+}
+
+// migration rewrites the Dart files of a project from one state manager to
+// another.
+type migration struct {
+	Root    string
+	Package string
+	From    string
+	To      string
+	// Files are the Dart files of lib/ and test/ by slash-separated path
+	// relative to Root, Original their content before the migration
+	Files    map[string]string
+	Original map[string]string
+	Holders  []*stateHolder
+	Notes    []migrationNote
+	noted    map[string]bool
+}
+
+func migrateCommand(args []string) {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	project := flags.String("project", ".", "path of the Flutter project")
+	from := flags.String("from", "", "state manager of the project: provider or scoped-model (detected by default)")
+	to := flags.String("to", "", "state manager to migrate to: riverpod, cubit or bloc (the same as cubit)")
+	dryRun := flags.Bool("dry-run", false, "only report what would change")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch migrate --to riverpod|cubit|bloc [flags]")
+		flags.PrintDefaults()
+	}
+	if positional := parseInterspersed(flags, args); len(positional) != 0 || *to == "" {
+		flags.Usage()
+		return
+	}
+
+	target, ok := migrateTargets[*to]
+	if !ok {
+		fmt.Printf("Unknown --to state manager %s, use riverpod, cubit or bloc.\n", *to)
+		return
+	}
+	if *to == "bloc" {
+		fmt.Println("A Bloc needs an event per method, written by hand: the state holders become Cubits of flutter_bloc, which keep their methods and can be turned into Blocs later.")
+	}
+	projectPath := *project
+	source := ""
+	if *from != "" {
+		if source, ok = migrateSources[*from]; !ok {
+			fmt.Printf("Unknown --from state manager %s, use provider or scoped-model.\n", *from)
+			return
+		}
+	} else {
+		_, detected, err := detectArchitecture(projectPath)
+		if err != nil {
+			fmt.Println("Error detecting the state manager:", err)
+			return
+		}
+		if detected != Provider && detected != ScopedModel {
+			fmt.Printf("The project uses %s, only Provider and Scoped Model projects can be migrated.\n", detected)
+			return
+		}
+		source = detected
+	}
+
+	m, err := newMigration(projectPath, source, target)
+	if err != nil {
+		fmt.Println("Error reading the Dart files:", err)
+		return
+	}
+	fmt.Printf("Migrating %s from %s to %s\n", m.Package, shortName(source), target)
+	m.run()
+	if len(m.Holders) == 0 {
+		fmt.Printf("No %s state holder could be migrated.\n", shortName(source))
+		m.printNotes()
+		return
+	}
+	m.printReport(*dryRun)
+	if *dryRun {
+		fmt.Println("Dry run, nothing was written.")
+		return
+	}
+
+	for _, file := range m.changedFiles() {
+		createFile(filepath.Join(projectPath, filepath.FromSlash(file)), m.Files[file])
+	}
+	m.updateDependencies()
+	m.tidy()
+	if m.importsSource() {
+		fmt.Printf("The project still uses %s, %s keeps recording it until the migration is finished.\n", shortName(source), manifestFile)
+		return
+	}
+	m.updateManifest()
+}
+
+func newMigration(root, from, to string) (*migration, error) {
+	m := &migration{
+		Root:     root,
+		Package:  pubspecName(root),
+		From:     from,
+		To:       to,
+		Files:    make(map[string]string),
+		Original: make(map[string]string),
+		noted:    make(map[string]bool),
+	}
+	for _, dir := range []string{"lib", "test"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if entry.IsDir() || !strings.HasSuffix(filePath, ".dart") || isGeneratedDart(filePath) {
+				return nil
+			}
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, filePath)
+			if err != nil {
+				return err
+			}
+			file := filepath.ToSlash(rel)
+			m.Files[file] = string(content)
+			m.Original[file] = string(content)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// run rewrites the state holders, where they are registered and the widgets
+// using them, then lists what is left.
+func (m *migration) run() {
+	m.findHolders()
+	m.plan()
+	if len(m.Holders) == 0 {
+		return
+	}
+	for _, file := range m.sortedFiles() {
+		m.rewriteRegistrations(file)
+	}
+	if m.To == Riverpod {
+		m.addProviderScope()
+	}
+	for _, file := range m.sortedFiles() {
+		m.rewriteConsumers(file)
+	}
+	if m.To == Riverpod {
+		for _, file := range m.sortedFiles() {
+			m.rewriteWidgets(file)
+		}
+	}
+	for _, h := range m.Holders {
+		m.rewriteHolder(h)
+	}
+	for _, file := range m.sortedFiles() {
+		m.fixImports(file)
+	}
+	for _, file := range m.changedFiles() {
+		m.Files[file] = tidyBlankLines(m.Files[file])
+	}
+	m.scanLeftovers()
+}
+
+func (m *migration) sortedFiles() []string {
+	var files []string
+	for file := range m.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (m *migration) changedFiles() []string {
+	var files []string
+	for _, file := range m.sortedFiles() {
+		if m.Files[file] != m.Original[file] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// note records code of file at pos the user has to migrate.
+func (m *migration) note(file string, pos int, format string, args ...interface{}) {
+	content := m.Files[file]
+	snippet := lineText(content, pos)
+	key := file + "\x00" + snippet
+	if m.noted[key] {
+		return
+	}
+	m.noted[key] = true
+	m.Notes = append(m.Notes, migrationNote{
+		File:    file,
+		Line:    lineOf(content, pos),
+		Snippet: snippet,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (m *migration) holder(name string) *stateHolder {
+	for _, h := range m.Holders {
+		if h.Name == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// findHolders collects the state holders whose state fits in one value, the
+// state of a Notifier or a Cubit.
+func (m *migration) findHolders() {
+	for _, file := range m.sortedFiles() {
+		content := m.Files[file]
+		if m.From == ScopedModel && !strings.Contains(content, "package:scoped_model/") {
+			continue
+		}
+		for _, match := range holderPatterns[m.From].FindAllStringSubmatchIndex(content, -1) {
+			open := match[1] - 1
+			close := matchingBracket(content, open)
+			if close < 0 {
+				continue
+			}
+			if h := m.analyzeHolder(file, content[match[2]:match[3]], match[0], open, close); h != nil {
+				m.Holders = append(m.Holders, h)
+			}
+		}
+	}
+}
+
+func (m *migration) analyzeHolder(file, name string, start, open, close int) *stateHolder {
+	content := m.Files[file]
+	h := &stateHolder{Name: name, File: file, Getters: make(map[string]bool), Methods: make(map[string]bool)}
+	getters := make(map[string]string)
+	var mutable []string
+	ctors := 0
+	for _, member := range classMembers(content, open, close) {
+		info := describeMember(name, member.Head)
+		switch info.Kind {
+		case fieldMember:
+			if !info.Mutable {
+				continue
+			}
+			mutable = append(mutable, info.Name)
+			h.Field, h.StateType, h.Initial = info.Name, info.Type, info.Init
+			if info.Init != "" && expressionEnd(info.Init, 0) < len(info.Init) {
+				// Several fields in one declaration
+				mutable = append(mutable, "")
+			}
+		case getterMember:
+			getters[info.Name] = strings.TrimPrefix(strings.TrimSpace(info.Body), "this.")
+		case setterMember, methodMember:
+			h.Methods[info.Name] = true
+			if info.Name == "dispose" {
+				cleanup := "override close() and call super.close()"
+				if m.To == Riverpod {
+					cleanup = "register the cleanup with ref.onDispose in build()"
+				}
+				m.note(file, member.Start, "%s overrides dispose(), which %s does not have: %s", name, m.To, cleanup)
+			}
+		case ctorMember:
+			ctors++
+			head := member.Head
+			if paren := strings.IndexByte(head, '('); paren >= 0 {
+				if end := matchingBracket(head, paren); end > paren && strings.TrimSpace(head[paren+1:end]) != "" {
+					h.NeedsArgs = true
+				}
+			}
+			if strings.HasPrefix(head, "factory ") && m.To == Riverpod {
+				m.note(file, member.Start, "%s has a factory constructor, create it in the provider by hand", name)
+				return nil
+			}
+		}
+	}
+
+	holderKind := "a Notifier"
+	if m.To == Cubit {
+		holderKind = "a Cubit"
+	}
+	switch {
+	case len(mutable) == 0:
+		m.note(file, start, "%s has no mutable field to keep as its state, migrate it by hand", name)
+		return nil
+	case len(mutable) > 1:
+		var names []string
+		for _, field := range mutable {
+			if field != "" {
+				names = append(names, field)
+			}
+		}
+		m.note(file, start, "%s keeps its state in several fields (%s) while %s keeps one value: merge them into a state class, then migrate it by hand", name, strings.Join(names, ", "), holderKind)
+		return nil
+	case !strings.HasPrefix(h.Field, "_") || h.StateType == "" || h.StateType == "var" || h.Initial == "":
+		m.note(file, start, "%s keeps its state in %s, which needs a private name, a type and an initial value to be migrated", name, h.Field)
+		return nil
+	case ctors > 1 && m.To == Riverpod:
+		m.note(file, start, "%s has several constructors while a Notifier is created by its provider, migrate it by hand", name)
+		return nil
+	}
+	if m.To == Cubit && regexp.MustCompile(`\b_\w+|\bthis\.`).MatchString(h.Initial) {
+		m.note(file, start, "the initial state of %s reads its fields, which the super constructor of Cubit cannot", name)
+	}
+
+	for getter, body := range getters {
+		if body == h.Field && h.Getter == "" {
+			h.Getter = getter
+			continue
+		}
+		h.Getters[getter] = true
+	}
+	return h
+}
+
+var (
+	createClosurePattern = regexp.MustCompile(`^\(\s*(\w*)\s*\)\s*=>\s*`)
+	holderCallPattern    = regexp.MustCompile(`^(\w+)(?:\.\w+)?\(`)
+	topLevelNamePattern  = regexp.MustCompile(`(?m)^(?:[A-Za-z_][\w<>?, ]*\s)?(\w+)\s*[=(]`)
+)
+
+// plan finds how each holder is created, from where it is registered, and
+// names the Riverpod providers.
+func (m *migration) plan() {
+	for _, file := range m.sortedFiles() {
+		content := m.Files[file]
+		for _, site := range findRegistrations(content) {
+			h := m.holder(site.Holder)
+			if h == nil || h.Create != "" || site.Create == "" || !strings.HasPrefix(file, "lib/") {
+				continue
+			}
+			if m.To == Riverpod && (len(identifierUses(site.Create, 0, len(site.Create), "context")) > 0 ||
+				site.Param != "" && site.Param != "_" && len(identifierUses(site.Create, 0, len(site.Create), site.Param)) > 0) {
+				m.note(file, site.Start, "%s is created from the BuildContext, which a provider does not have", h.Name)
+				continue
+			}
+			h.Create = site.Create
+			h.CreateImports = m.createImports(file, site.Create, h)
+		}
+	}
+
+	taken := make(map[string]bool)
+	for _, content := range m.Files {
+		for _, match := range topLevelNamePattern.FindAllStringSubmatch(content, -1) {
+			taken[match[1]] = true
+		}
+	}
+	var holders []*stateHolder
+	for _, h := range m.Holders {
+		if m.To == Riverpod && h.Create == "" {
+			if h.NeedsArgs {
+				m.note(h.File, strings.Index(m.Files[h.File], "class "+h.Name), "%s is not registered anywhere it can be created from, write its provider by hand", h.Name)
+				continue
+			}
+			h.Create = h.Name + "()"
+		}
+		if m.To == Riverpod {
+			h.Provider = providerName(h.Name, taken)
+			taken[h.Provider] = true
+		}
+		holders = append(holders, h)
+	}
+	m.Holders = holders
+}
+
+// providerName names the provider of a holder after its feature, like the
+// counterProvider of the Riverpod templates.
+func providerName(holder string, taken map[string]bool) string {
+	base := holder
+	for _, suffix := range []string{"ViewModel", "Controller", "Provider", "Notifier", "Model", "Store"} {
+		if strings.HasSuffix(base, suffix) && base != suffix {
+			base = strings.TrimSuffix(base, suffix)
+			break
+		}
+	}
+	name := lowerFirst(base) + "Provider"
+	if taken[name] {
+		name = lowerFirst(holder) + "Provider"
+	}
+	return name
+}
+
+// createImports returns the import directives of file the expression
+// creating h needs, with package: URIs: the prefixed imports it uses and
+// the imports of the project declaring its identifiers.
+func (m *migration) createImports(file, expr string, h *stateHolder) []string {
+	words := make(map[string]bool)
+	for _, loc := range dartWordPattern.FindAllStringIndex(expr, -1) {
+		word := expr[loc[0]:loc[1]]
+		if loc[0] > 0 && expr[loc[0]-1] == '.' || word == h.Name {
+			continue
+		}
+		if rest := strings.TrimLeft(expr[loc[1]:], " "); strings.HasPrefix(rest, ":") {
+			// A named argument
+			continue
+		}
+		words[word] = true
+	}
+
+	var directives []string
+	content := m.Files[file]
+	for _, match := range importDirectivePattern.FindAllStringSubmatchIndex(content, -1) {
+		directive := content[match[0]:match[1]]
+		uri, prefix := content[match[2]:match[3]], ""
+		if match[4] >= 0 {
+			prefix = content[match[4]:match[5]]
+		}
+		target, ok := importTarget(m.Package, strings.TrimPrefix(file, "lib/"), uri)
+		local := ok && !strings.HasPrefix(target, "package:")
+		if local {
+			if "lib/"+target == h.File {
+				continue
+			}
+			directive = strings.Replace(directive, uri, "package:"+m.Package+"/"+target, 1)
+		}
+		switch {
+		case prefix != "":
+			if strings.Contains(expr, prefix+".") {
+				directives = append(directives, directive)
+			}
+		case local:
+			for word := range words {
+				if declaresTopLevel(m.Files["lib/"+target], word) {
+					directives = append(directives, directive)
+					break
+				}
+			}
+		}
+	}
+	return directives
+}
+
+// declaresTopLevel reports whether a Dart file declares name outside its
+// classes.
+func declaresTopLevel(content, name string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '}' || strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if len(identifierUses(line, 0, len(line), name)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// registrationSite is where a holder is provided to the widget tree: a
+// ChangeNotifierProvider or a ScopedModel.
+type registrationSite struct {
+	// Start is where the call starts, NameEnd where its name ends, Open and
+	// Close are its parentheses
+	Start, NameEnd, Open, Close int
+	Holder                      string
+	// Create is the expression creating the holder, Param the parameter of
+	// the create callback
+	Create string
+	Param  string
+	// Instance is the existing holder given to ChangeNotifierProvider.value
+	// or ScopedModel
+	Instance string
+	Args     []callArg
+	// FieldStart and FieldEnd delimit the field holding the instance given
+	// to ScopedModel, when it is created there
+	FieldStart, FieldEnd int
+}
+
+var (
+	changeNotifierProviderPattern = regexp.MustCompile(`\bChangeNotifierProvider(\.value)?(?:<(\w+)>)?\(`)
+	scopedModelPattern            = regexp.MustCompile(`\bScopedModel<(\w+)>\(`)
+	multiProviderPattern          = regexp.MustCompile(`\bMultiProvider\(`)
+)
+
+func findRegistrations(content string) []registrationSite {
+	var sites []registrationSite
+	for _, match := range changeNotifierProviderPattern.FindAllStringSubmatchIndex(content, -1) {
+		site := registrationSite{Start: match[0], NameEnd: match[0] + len("ChangeNotifierProvider"), Open: match[1] - 1}
+		if site.Close = matchingBracket(content, site.Open); site.Close < 0 {
+			continue
+		}
+		site.FieldStart, site.FieldEnd = -1, -1
+		site.Args = callArgs(content, site.Open, site.Close)
+		if match[4] >= 0 {
+			site.Holder = content[match[4]:match[5]]
+		}
+		if match[2] >= 0 {
+			if value, ok := namedArg(site.Args, "value"); ok {
+				site.Instance = content[value.Start:value.End]
+			}
+		} else if create, ok := namedArg(site.Args, "create"); ok {
+			value := content[create.Start:create.End]
+			if closure := createClosurePattern.FindStringSubmatch(value); closure != nil {
+				expr := strings.TrimSpace(value[len(closure[0]):])
+				if call := holderCallPattern.FindStringSubmatch(expr); call != nil && (site.Holder == "" || site.Holder == call[1]) {
+					site.Holder, site.Create, site.Param = call[1], expr, closure[1]
+				}
+			}
+		}
+		sites = append(sites, site)
+	}
+
+	for _, match := range scopedModelPattern.FindAllStringSubmatchIndex(content, -1) {
+		site := registrationSite{Start: match[0], NameEnd: match[1] - 1, Open: match[1] - 1, Holder: content[match[2]:match[3]], FieldStart: -1, FieldEnd: -1}
+		if site.Close = matchingBracket(content, site.Open); site.Close < 0 {
+			continue
+		}
+		site.Args = callArgs(content, site.Open, site.Close)
+		model, ok := namedArg(site.Args, "model")
+		if !ok {
+			continue
+		}
+		expr := content[model.Start:model.End]
+		if call := holderCallPattern.FindStringSubmatch(expr); call != nil && call[1] == site.Holder {
+			site.Create = expr
+		} else if isDartIdentifier(expr) {
+			site.Instance = expr
+			field := regexp.MustCompile(`(?m)^[ \t]*(?:late\s+)?(?:final|var)\s+(?:` + site.Holder + `\s+)?` + expr + `\s*=\s*`)
+			if loc := field.FindStringIndex(content); loc != nil {
+				end := expressionEnd(content, loc[1])
+				init := strings.TrimSpace(content[loc[1]:end])
+				if call := holderCallPattern.FindStringSubmatch(init); call != nil && call[1] == site.Holder && end < len(content) && content[end] == ';' {
+					site.Create = init
+					site.FieldStart, site.FieldEnd = wholeLines(content, loc[0]+len(content[loc[0]:loc[1]])-len(strings.TrimLeft(content[loc[0]:loc[1]], " \t")), end+1)
+				}
+			}
+		}
+		sites = append(sites, site)
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Start < sites[j].Start })
+	return sites
+}
+
+// fieldOnlyProvided reports whether the field holding the instance given to
+// a registration is used nowhere else in its class.
+func fieldOnlyProvided(content string, site registrationSite) bool {
+	start, end := 0, len(content)
+	if stack := openBrackets(content, site.FieldStart); len(stack) > 0 {
+		start = stack[len(stack)-1]
+		if close := matchingBracket(content, start); close > 0 {
+			end = close
+		}
+	}
+	model, _ := namedArg(site.Args, "model")
+	uses := 0
+	for _, use := range identifierUses(content, start, end, site.Instance) {
+		if use != model.ArgStart {
+			uses++
+		}
+	}
+	return uses == 2
+}
+
+func isDartIdentifier(s string) bool {
+	return s != "" && dartWordPattern.FindString(s) == s
+}
+
+// rewriteRegistrations replaces the registrations of the migrated holders:
+// Riverpod providers are global, so they go away, and a ProviderScope takes
+// the place of the outermost one of the app; Cubits are provided by
+// BlocProvider.
+func (m *migration) rewriteRegistrations(file string) {
+	if m.To == Cubit {
+		m.Files[file] = applyEdits(m.Files[file], m.blocProviderEdits(file))
+		return
+	}
+	for {
+		edits := m.nextRegistrationEdits(file)
+		if len(edits) == 0 {
+			return
+		}
+		m.Files[file] = applyEdits(m.Files[file], edits)
+	}
+}
+
+// nextRegistrationEdits returns the edits removing one registration of file,
+// the last one so that inner registrations go before the outer ones.
+func (m *migration) nextRegistrationEdits(file string) []textEdit {
+	content := m.Files[file]
+	sites := findRegistrations(content)
+	root := strings.HasPrefix(file, "test/") || strings.Contains(content, "runApp(")
+
+	// MultiProvider lists whose entries are all gone
+	multis := multiProviderPattern.FindAllStringIndex(content, -1)
+	for i := len(multis) - 1; i >= 0; i-- {
+		open := multis[i][1] - 1
+		close := matchingBracket(content, open)
+		if close < 0 {
+			continue
+		}
+		args := callArgs(content, open, close)
+		providers, ok := namedArg(args, "providers")
+		if !ok || !strings.HasPrefix(content[providers.Start:providers.End], "[") {
+			continue
+		}
+		if strings.TrimSpace(strings.Trim(content[providers.Start:providers.End], "[]")) == "" {
+			if edit, ok := m.unwrap(file, multis[i][0], close, args, root); ok {
+				return []textEdit{edit}
+			}
+		}
+	}
+
+	for i := len(sites) - 1; i >= 0; i-- {
+		site := sites[i]
+		h := m.holder(site.Holder)
+		if h == nil {
+			continue
+		}
+		if site.Create == "" {
+			m.note(file, site.Start, "provides an existing %s, with Riverpod override %s in a ProviderScope instead", h.Name, h.Provider)
+			continue
+		}
+		if strings.Join(strings.Fields(site.Create), "") != strings.Join(strings.Fields(h.Create), "") {
+			m.note(file, site.Start, "creates %s with other arguments than %s, override the provider in a ProviderScope to keep them", h.Name, h.Provider)
+		}
+
+		var edits []textEdit
+		if site.FieldStart >= 0 && fieldOnlyProvided(content, site) {
+			edits = append(edits, textEdit{site.FieldStart, site.FieldEnd, ""})
+		}
+		if _, ok := namedArg(site.Args, "child"); ok {
+			edit, _ := m.unwrap(file, site.Start, site.Close, site.Args, root)
+			return append(edits, edit)
+		}
+		// An entry of MultiProvider
+		end := site.Close + 1
+		for end < len(content) && (content[end] == ' ' || content[end] == '\t') {
+			end++
+		}
+		if end < len(content) && content[end] == ',' {
+			end++
+		}
+		start, end := wholeLines(content, site.Start, end)
+		return append(edits, textEdit{start, end, ""})
+	}
+	return nil
+}
+
+// unwrap returns the edit replacing the registration between start and
+// close by its child, wrapped in a ProviderScope when it is the outermost
+// registration of the app or of a test.
+func (m *migration) unwrap(file string, start, close int, args []callArg, root bool) (textEdit, bool) {
+	content := m.Files[file]
+	child, ok := namedArg(args, "child")
+	if !ok {
+		m.note(file, start, "has no child to keep once the providers are gone")
+		return textEdit{}, false
+	}
+	text := content[child.Start:child.End]
+	if root && !insideCall(content, start, "ChangeNotifierProvider", "MultiProvider", "ScopedModel", "ProviderScope") {
+		text = providerScope(text)
+	}
+	return textEdit{start, close + 1, text}, true
+}
+
+// providerScope wraps a widget in a ProviderScope, constant when the widget
+// is.
+func providerScope(child string) string {
+	if strings.HasPrefix(child, "const ") {
+		return "const ProviderScope(child: " + strings.TrimPrefix(child, "const ") + ")"
+	}
+	return "ProviderScope(child: " + child + ")"
+}
+
+// insideCall reports whether pos is in the arguments of a call of one of
+// the names.
+func insideCall(content string, pos int, names ...string) bool {
+	for _, open := range openBrackets(content, pos) {
+		if content[open] != '(' {
+			continue
+		}
+		before := strings.TrimRight(content[:open], " \t\n")
+		if i := strings.LastIndexByte(before, '<'); i >= 0 && strings.HasSuffix(before, ">") {
+			before = before[:i]
+		}
+		for _, name := range names {
+			if strings.HasSuffix(before, name) && (len(before) == len(name) || !isIdentByte(before[len(before)-len(name)-1])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// addProviderScope wraps the app given to runApp in a ProviderScope when no
+// registration made room for one.
+func (m *migration) addProviderScope() {
+	for _, content := range m.Files {
+		if strings.Contains(content, "ProviderScope(") {
+			return
+		}
+	}
+	for _, file := range m.sortedFiles() {
+		content := m.Files[file]
+		at := strings.Index(content, "runApp(")
+		if !strings.HasPrefix(file, "lib/") || at < 0 {
+			continue
+		}
+		open := at + len("runApp")
+		close := matchingBracket(content, open)
+		if close < 0 {
+			continue
+		}
+		args := callArgs(content, open, close)
+		if len(args) != 1 {
+			continue
+		}
+		app := content[args[0].Start:args[0].End]
+		m.Files[file] = applyEdits(content, []textEdit{{args[0].Start, args[0].End, providerScope(app)}})
+		m.note(file, at, "the app is wrapped in a ProviderScope, widget tests pumping widgets that read providers need one too")
+		return
+	}
+}
+
+// blocProviderEdits turns the registrations of the migrated holders of file
+// into BlocProviders.
+func (m *migration) blocProviderEdits(file string) []textEdit {
+	content := m.Files[file]
+	sites := findRegistrations(content)
+	var edits []textEdit
+	migrated := make(map[int]bool)
+	for _, site := range sites {
+		h := m.holder(site.Holder)
+		if h == nil {
+			continue
+		}
+		migrated[site.Start] = true
+		if content[site.Start:site.NameEnd] == "ChangeNotifierProvider" {
+			edits = append(edits, textEdit{site.Start, site.NameEnd, "BlocProvider"})
+			continue
+		}
+
+		model, _ := namedArg(site.Args, "model")
+		if site.Create != "" {
+			edits = append(edits,
+				textEdit{site.Start, site.Open + 1, "BlocProvider("},
+				textEdit{model.ArgStart, model.End, "create: (_) => " + site.Create})
+			if site.FieldStart >= 0 && fieldOnlyProvided(content, site) {
+				edits = append(edits, textEdit{site.FieldStart, site.FieldEnd, ""})
+			}
+			continue
+		}
+		edits = append(edits,
+			textEdit{site.Start, site.Open + 1, "BlocProvider.value("},
+			textEdit{model.ArgStart, model.Start, "value: "})
+	}
+
+	// MultiProvider becomes MultiBlocProvider once it only holds Cubits
+	for _, loc := range multiProviderPattern.FindAllStringIndex(content, -1) {
+		open := loc[1] - 1
+		close := matchingBracket(content, open)
+		if close < 0 {
+			continue
+		}
+		providers, ok := namedArg(callArgs(content, open, close), "providers")
+		value := content[providers.Start:providers.End]
+		if !ok || !strings.HasPrefix(value, "[") {
+			continue
+		}
+		all := true
+		for _, entry := range callArgs(content, providers.Start, providers.Start+len(value)-1) {
+			all = all && migrated[entry.Start]
+		}
+		if all {
+			edits = append(edits, textEdit{loc[0], loc[0] + len("MultiProvider"), "MultiBlocProvider"})
+		}
+	}
+	return edits
+}
+
+// lookupPatterns return the patterns of the expressions looking up a holder
+// from a BuildContext, listening to it or not.
+func lookupPatterns(holder string) (watch, read string) {
+	watch = `\b(\w+)\.watch<` + holder + `>\(\)|\bProvider\.of<` + holder + `>\(\s*(\w+)\s*,?\s*\)|\bScopedModel\.of<` + holder + `>\(\s*(\w+)\s*,\s*rebuildOnChange:\s*true\s*,?\s*\)`
+	read = `\b(\w+)\.read<` + holder + `>\(\)|\bProvider\.of<` + holder + `>\(\s*(\w+)\s*,\s*listen:\s*false\s*,?\s*\)|\bScopedModel\.of<` + holder + `>\(\s*(\w+)\s*(?:,\s*rebuildOnChange:\s*false\s*)?,?\s*\)`
+	return watch, read
+}
+
+// firstGroup returns the first group a match captured.
+func firstGroup(content string, match []int) string {
+	for i := 2; i+1 < len(match); i += 2 {
+		if match[i] >= 0 {
+			return content[match[i]:match[i+1]]
+		}
+	}
+	return ""
+}
+
+// rewriteConsumers rewrites the widgets looking up the migrated holders.
+func (m *migration) rewriteConsumers(file string) {
+	for _, h := range m.Holders {
+		var edits []textEdit
+		if m.To == Riverpod {
+			edits = m.riverpodLookupEdits(file, h)
+		} else {
+			edits = m.blocLookupEdits(file, h)
+		}
+		edits = append(edits, m.builderEdits(file, h)...)
+		m.Files[file] = applyEdits(m.Files[file], edits)
+	}
+}
+
+// memberAccess returns what reading member of h from a widget becomes,
+// watching it or not, with ctx the BuildContext for Cubits.
+func (m *migration) memberAccess(file string, pos int, h *stateHolder, member string, watch bool, ctx string) string {
+	if m.To == Cubit {
+		if member == h.Getter {
+			return "state"
+		}
+		return ctx + ".read<" + h.Name + ">()." + member
+	}
+
+	read := "read"
+	if watch {
+		read = "watch"
+	}
+	switch {
+	case member == h.Getter:
+		return "ref." + read + "(" + h.Provider + ")"
+	case h.Methods[member]:
+	case h.Getters[member]:
+		if watch {
+			m.note(file, pos, "reads %s, which is not the state of %s, so the widget does not rebuild when it changes", member, h.Name)
+		}
+		return "ref." + read + "(" + h.Provider + ".notifier)." + member
+	default:
+		m.note(file, pos, "uses %s, which %s does not declare", member, h.Name)
+	}
+	return "ref.read(" + h.Provider + ".notifier)." + member
+}
+
+var memberPattern = regexp.MustCompile(`^\??\.(\w+)`)
+
+// variableEdits rewrites the uses of variable v holding h between start and
+// end, and reports whether some are left, where v is passed as a whole.
+func (m *migration) variableEdits(file string, h *stateHolder, v string, start, end int, watch bool, ctx string) ([]textEdit, bool) {
+	content := m.Files[file]
+	var edits []textEdit
+	bare := false
+	for _, use := range identifierUses(content, start, end, v) {
+		after := use + len(v)
+		member := memberPattern.FindStringSubmatch(content[after:])
+		if member == nil {
+			bare = true
+			continue
+		}
+		memberEnd := after + len(member[0])
+		rest := strings.TrimLeft(content[memberEnd:], " ")
+		if member[1] == h.Getter && strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "==") {
+			m.note(file, use, "assigns %s of %s, set the state in a method of the holder instead", member[1], h.Name)
+			bare = true
+			continue
+		}
+		access := m.memberAccess(file, use, h, member[1], watch, ctx)
+		if isDartIdentifier(access) && strings.HasSuffix(content[:use], "${") && strings.HasPrefix(content[memberEnd:], "}") &&
+			(memberEnd+1 >= len(content) || !isIdentByte(content[memberEnd+1])) {
+			// '${model.count}' becomes '$state'
+			edits = append(edits, textEdit{use - 2, memberEnd + 1, "$" + access})
+			continue
+		}
+		edits = append(edits, textEdit{use, memberEnd, access})
+	}
+	return edits, bare
+}
+
+// riverpodLookupEdits replaces the lookups of h from a BuildContext by reads
+// of its provider.
+func (m *migration) riverpodLookupEdits(file string, h *stateHolder) []textEdit {
+	content := m.Files[file]
+	watch, read := lookupPatterns(h.Name)
+	var edits []textEdit
+	var declarations [][2]int
+
+	// Variables holding the holder
+	for _, mode := range []struct {
+		pattern string
+		watch   bool
+	}{{watch, true}, {read, false}} {
+		pattern := regexp.MustCompile(`(?m)^[ \t]*(?:final|var)\s+(?:` + h.Name + `\s+)?(\w+)\s*=\s*(?:` + mode.pattern + `)\s*;[ \t]*\n?`)
+		for _, match := range pattern.FindAllStringSubmatchIndex(content, -1) {
+			v := content[match[2]:match[3]]
+			stack := openBrackets(content, match[0])
+			if len(stack) == 0 {
+				continue
+			}
+			end := matchingBracket(content, stack[len(stack)-1])
+			if end < 0 {
+				end = len(content)
+			}
+			uses, bare := m.variableEdits(file, h, v, match[1], end, mode.watch, "")
+			edits = append(edits, uses...)
+			declarations = append(declarations, [2]int{match[0], match[1]})
+			if !bare {
+				edits = append(edits, textEdit{match[0], match[1], ""})
+				continue
+			}
+			indent := content[match[0] : match[0]+len(content[match[0]:match[1]])-len(strings.TrimLeft(content[match[0]:match[1]], " \t"))]
+			edits = append(edits, textEdit{match[0], match[1], indent + "final " + v + " = ref.read(" + h.Provider + ".notifier);\n"})
+			m.note(file, match[0], "passes %s around as a whole, the widgets given it do not rebuild when the state of %s changes", v, h.Name)
+			if v == h.Provider {
+				m.note(file, match[0], "%s hides the provider of the same name, rename it", v)
+			}
+		}
+	}
+	inDeclaration := func(pos int) bool {
+		for _, d := range declarations {
+			if pos >= d[0] && pos < d[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Lookups used in place
+	for _, mode := range []struct {
+		pattern string
+		watch   bool
+	}{{watch, true}, {read, false}} {
+		for _, loc := range regexp.MustCompile(mode.pattern).FindAllStringIndex(content, -1) {
+			if inDeclaration(loc[0]) {
+				continue
+			}
+			if member := memberPattern.FindStringSubmatch(content[loc[1]:]); member != nil {
+				edits = append(edits, textEdit{loc[0], loc[1] + len(member[0]), m.memberAccess(file, loc[0], h, member[1], mode.watch, "")})
+				continue
+			}
+			if mode.watch {
+				m.note(file, loc[0], "watches %s as a whole, watch %s to rebuild when its state changes", h.Name, h.Provider)
+				edits = append(edits, textEdit{loc[0], loc[1], "ref.watch(" + h.Provider + ".notifier)"})
+				continue
+			}
+			edits = append(edits, textEdit{loc[0], loc[1], "ref.read(" + h.Provider + ".notifier)"})
+		}
+	}
+
+	// Selections of the state
+	selection := regexp.MustCompile(`\b\w+\.select<` + h.Name + `\s*,[^>]*>\(\s*\(\s*(\w+)\s*\)\s*=>\s*(\w+)\.(\w+)\s*,?\s*\)`)
+	for _, match := range selection.FindAllStringSubmatchIndex(content, -1) {
+		if content[match[2]:match[3]] == content[match[4]:match[5]] && content[match[6]:match[7]] == h.Getter {
+			edits = append(edits, textEdit{match[0], match[1], "ref.watch(" + h.Provider + ")"})
+		}
+	}
+	return edits
+}
+
+// blocLookupEdits replaces the lookups of provider and scoped_model by the
+// BuildContext extensions flutter_bloc exports.
+func (m *migration) blocLookupEdits(file string, h *stateHolder) []textEdit {
+	content := m.Files[file]
+	watch, read := lookupPatterns(h.Name)
+	var edits []textEdit
+	for method, pattern := range map[string]string{"watch": watch, "read": read} {
+		for _, match := range regexp.MustCompile(pattern).FindAllStringSubmatchIndex(content, -1) {
+			if !strings.HasPrefix(content[match[0]:], "Provider.") && !strings.HasPrefix(content[match[0]:], "ScopedModel.") {
+				continue
+			}
+			edits = append(edits, textEdit{match[0], match[1], firstGroup(content, match) + "." + method + "<" + h.Name + ">()"})
+		}
+	}
+	return edits
+}
+
+var builderParamsPattern = regexp.MustCompile(`^\(\s*(\w+)\s*,\s*(\w+)\s*,\s*(\w+)\s*\)`)
+
+// builderEdits rewrites the Consumer or ScopedModelDescendant widgets
+// building from h into Riverpod Consumers or BlocBuilders.
+func (m *migration) builderEdits(file string, h *stateHolder) []textEdit {
+	content := m.Files[file]
+	widget := "Consumer"
+	if m.From == ScopedModel {
+		widget = "ScopedModelDescendant"
+	}
+	var edits []textEdit
+	for _, loc := range regexp.MustCompile(`\b`+widget+`<`+h.Name+`>\(`).FindAllStringIndex(content, -1) {
+		open := loc[1] - 1
+		close := matchingBracket(content, open)
+		if close < 0 {
+			continue
+		}
+		args := callArgs(content, open, close)
+		builder, ok := namedArg(args, "builder")
+		if !ok {
+			continue
+		}
+		params := builderParamsPattern.FindStringSubmatch(content[builder.Start:builder.End])
+		if params == nil {
+			m.note(file, loc[0], "builds from %s with a builder the migration does not understand", h.Name)
+			continue
+		}
+		ctx, v, child := params[1], params[2], params[3]
+		if m.From == ScopedModel {
+			v, child = params[3], params[2]
+		}
+		bodyStart := builder.Start + len(params[0])
+
+		if m.To == Riverpod {
+			edits = append(edits,
+				textEdit{loc[0], loc[1], "Consumer("},
+				textEdit{builder.Start, bodyStart, "(" + ctx + ", ref, " + child + ")"})
+			uses, bare := m.variableEdits(file, h, v, bodyStart, builder.End, true, "")
+			if bare {
+				m.note(file, loc[0], "passes %s around as a whole, read %s in the widgets given it", v, h.Provider)
+			}
+			edits = append(edits, uses...)
+			continue
+		}
+
+		if ctx == "_" {
+			ctx = "context"
+		}
+		if len(identifierUses(content, bodyStart, builder.End, child)) > 0 && child != "_" && child != "__" {
+			m.note(file, loc[0], "builds with the child of %s, which BlocBuilder does not have", widget)
+		}
+		if _, ok := namedArg(args, "child"); ok {
+			m.note(file, loc[0], "gives %s a child, which BlocBuilder does not have", widget)
+		}
+		edits = append(edits,
+			textEdit{loc[0], loc[1], "BlocBuilder<" + h.Name + ", " + h.StateType + ">("},
+			textEdit{builder.Start, bodyStart, "(" + ctx + ", state)"})
+		uses, bare := m.variableEdits(file, h, v, bodyStart, builder.End, true, ctx)
+		if bare {
+			m.note(file, loc[0], "passes %s around as a whole, read it with %s.read<%s>() instead", v, ctx, h.Name)
+		}
+		edits = append(edits, uses...)
+	}
+	return edits
+}
+
+var (
+	refPattern         = regexp.MustCompile(`\bref\.(?:watch|read)\(`)
+	classHeaderPattern = regexp.MustCompile(`(?m)^[ \t]*(?:abstract\s+)?class\s+(\w+)(?:<[^{]*?>)?\s+extends\s+(\w+)(?:<(\w+)>)?[^{]*\{$`)
+	buildPattern       = regexp.MustCompile(`Widget\s+build\(\s*BuildContext\s+(\w+)\s*,?\s*\)`)
+)
+
+// rewriteWidgets turns the widgets reading providers into ConsumerWidgets
+// and ConsumerStates, which have a WidgetRef.
+func (m *migration) rewriteWidgets(file string) {
+	content := m.Files[file]
+	edits := make(map[int]textEdit)
+	for _, loc := range refPattern.FindAllStringIndex(content, -1) {
+		if insideCall(content, loc[0], "Consumer") {
+			continue
+		}
+		stack := openBrackets(content, loc[0])
+		var header []int
+		var open, close int
+		for i := len(stack) - 1; i >= 0 && header == nil; i-- {
+			if content[stack[i]] != '{' {
+				continue
+			}
+			lineStart := strings.LastIndexByte(content[:stack[i]], '\n') + 1
+			if match := classHeaderPattern.FindStringSubmatchIndex(content[lineStart : stack[i]+1]); match != nil {
+				for j := range match {
+					if match[j] >= 0 {
+						match[j] += lineStart
+					}
+				}
+				header, open = match, stack[i]
+				close = matchingBracket(content, open)
+			}
+		}
+		if header == nil {
+			m.note(file, loc[0], "reads a provider outside of a widget, pass it a WidgetRef")
+			continue
+		}
+
+		class, base := content[header[2]:header[3]], content[header[4]:header[5]]
+		switch base {
+		case "StatelessWidget":
+			build := buildPattern.FindStringSubmatchIndex(content[open:close])
+			if build == nil {
+				m.note(file, loc[0], "%s reads a provider outside of build(), which is where a ConsumerWidget has a WidgetRef", class)
+				continue
+			}
+			buildStart, buildEnd := open+build[0], open+build[1]
+			bodyEnd := close
+			if brace := strings.IndexByte(content[buildEnd:close], '{'); brace >= 0 && !strings.HasPrefix(strings.TrimSpace(content[buildEnd:close]), "=>") {
+				bodyEnd = matchingBracket(content, buildEnd+brace)
+			}
+			if loc[0] < buildEnd || loc[0] > bodyEnd {
+				m.note(file, loc[0], "%s reads a provider outside of build(), which is where a ConsumerWidget has a WidgetRef", class)
+			}
+			edits[header[4]] = textEdit{header[4], header[5], "ConsumerWidget"}
+			edits[buildStart] = textEdit{buildStart, buildEnd, "Widget build(BuildContext " + content[open+build[2]:open+build[3]] + ", WidgetRef ref)"}
+		case "State":
+			if header[6] < 0 {
+				continue
+			}
+			widget := content[header[6]:header[7]]
+			edits[header[4]] = textEdit{header[4], header[5], "ConsumerState"}
+			stateful := regexp.MustCompile(`\bclass\s+` + widget + `\s+extends\s+(StatefulWidget)\b`).FindStringSubmatchIndex(content)
+			if stateful == nil {
+				m.note(file, loc[0], "%s is a State of %s, which has to become a ConsumerStatefulWidget", class, widget)
+				continue
+			}
+			edits[stateful[2]] = textEdit{stateful[2], stateful[3], "ConsumerStatefulWidget"}
+			if create := regexp.MustCompile(`\bState<` + widget + `>(\s+createState\()`).FindStringSubmatchIndex(content); create != nil {
+				edits[create[0]] = textEdit{create[0], create[2], "ConsumerState<" + widget + ">"}
+			}
+		case "ConsumerWidget", "ConsumerState", "HookConsumerWidget", "ConsumerStatefulWidget":
+		default:
+			m.note(file, loc[0], "%s extends %s, only ConsumerWidgets and ConsumerStates have a WidgetRef", class, base)
+		}
+	}
+
+	var list []textEdit
+	for _, edit := range edits {
+		list = append(list, edit)
+	}
+	m.Files[file] = applyEdits(content, list)
+}
+
+var (
+	notifyStatementPattern  = regexp.MustCompile(`(?m)^[ \t]*notifyListeners\(\);[ \t]*\n`)
+	notifyCallPattern       = regexp.MustCompile(`\s*\bnotifyListeners\(\);`)
+	stateAssignPattern      = regexp.MustCompile(`\bstate\s*(\+\+|--|\?\?=|~/=|[-+*/%]=|=)`)
+	statePrefixPattern      = regexp.MustCompile(`(\+\+|--)state\b`)
+	stateMutationPattern    = regexp.MustCompile(`\bstate(?:\.(?:add|addAll|insert|insertAll|remove|removeAt|removeLast|removeWhere|retainWhere|clear|sort|shuffle|putIfAbsent|update|updateAll)\(|\[[^\]]*\]\s*=[^=])`)
+	simpleExpressionPattern = regexp.MustCompile(`^[\w.]+$`)
+)
+
+// rewriteHolder turns h into a Notifier or a Cubit keeping its state in
+// state, and declares its provider for Riverpod.
+func (m *migration) rewriteHolder(h *stateHolder) {
+	content := m.Files[h.File]
+	header := regexp.MustCompile(`(?m)^class\s+` + h.Name + `\s+extends\s+(ChangeNotifier|Model)\b[^{]*\{`).FindStringSubmatchIndex(content)
+	if header == nil {
+		return
+	}
+	open := header[1] - 1
+	close := matchingBracket(content, open)
+	base := "Notifier<" + h.StateType + ">"
+	if m.To == Cubit {
+		base = "Cubit<" + h.StateType + ">"
+	}
+	edits := []textEdit{{header[2], header[3], base}}
+
+	var ctorBody []string
+	hasCtor := false
+	buildAt := -1
+	for _, member := range classMembers(content, open, close) {
+		text := content[member.Start:member.End]
+		info := describeMember(h.Name, member.Head)
+		switch {
+		case info.Kind == fieldMember && info.Name == h.Field:
+			if m.To == Riverpod {
+				buildAt = len(edits)
+				edits = append(edits, textEdit{member.Start, member.End, ""})
+				continue
+			}
+			start, end := wholeLines(content, member.Start, member.End)
+			edits = append(edits, textEdit{start, end, ""})
+		case info.Kind == getterMember && info.Name == h.Getter && m.To == Riverpod:
+			start, end := wholeLines(content, member.Start, member.End)
+			edits = append(edits, textEdit{start, end, ""})
+		case info.Kind == ctorMember:
+			hasCtor = true
+			rewritten, body := m.rewriteConstructor(h, text, member.Head)
+			ctorBody = append(ctorBody, body...)
+			if rewritten == "" {
+				start, end := wholeLines(content, member.Start, member.End)
+				edits = append(edits, textEdit{start, end, ""})
+				continue
+			}
+			edits = append(edits, textEdit{member.Start, member.End, rewritten})
+		default:
+			rewritten := m.rewriteStateUses(h, text)
+			if stateMutationPattern.MatchString(rewritten) {
+				update := "assign a new value to state"
+				if m.To == Cubit {
+					update = "emit a new value"
+				}
+				m.note(h.File, member.Start, "%s changes its state in place, which does not notify the widgets: %s instead", h.Name, update)
+			}
+			if strings.Contains(rewritten, "notifyListeners") {
+				m.note(h.File, member.Start, "%s calls notifyListeners in a way the migration does not understand", h.Name)
+			}
+			edits = append(edits, textEdit{member.Start, member.End, rewritten})
+		}
+	}
+
+	if m.To == Riverpod {
+		lineStart := strings.LastIndexByte(content[:edits[buildAt].Start], '\n') + 1
+		indent := content[lineStart:edits[buildAt].Start]
+		var build strings.Builder
+		fmt.Fprintf(&build, "@override\n%s%s build() {\n", indent, h.StateType)
+		for _, statement := range ctorBody {
+			fmt.Fprintf(&build, "%s  %s\n", indent, statement)
+		}
+		fmt.Fprintf(&build, "%s  return %s;\n%s}", indent, h.Initial, indent)
+		edits[buildAt].Text = build.String()
+	} else if !hasCtor {
+		edits = append(edits, textEdit{open + 1, open + 1, "\n  " + h.Name + "() : super(" + h.Initial + ");\n"})
+	}
+	content = applyEdits(content, edits)
+
+	if m.To == Riverpod {
+		create := "() => " + h.Create
+		if h.Create == h.Name+"()" {
+			create = h.Name + ".new"
+		}
+		at := strings.Index(content, "class "+h.Name+" ")
+		for at > 0 {
+			// Keep the doc comments and annotations on the class
+			prev := strings.LastIndexByte(content[:at-1], '\n') + 1
+			line := strings.TrimSpace(content[prev : at-1])
+			if !strings.HasPrefix(line, "///") && !strings.HasPrefix(line, "@") {
+				break
+			}
+			at = prev
+		}
+		declaration := fmt.Sprintf("final %s = NotifierProvider<%s, %s>(\n  %s,\n);\n\n", h.Provider, h.Name, h.StateType, create)
+		content = content[:at] + declaration + content[at:]
+		for _, directive := range h.CreateImports {
+			content = addDartDirective(content, directive)
+		}
+		content = addDartImport(content, migrationImports[Riverpod])
+	} else {
+		content = addDartImport(content, "package:bloc/bloc.dart")
+	}
+	m.Files[h.File] = tidyBlankLines(content)
+}
+
+// rewriteConstructor rewrites a constructor of h: a Notifier is built by
+// build(), which takes over the body of the constructor, a Cubit passes its
+// initial state to super. It returns the constructor, empty when it is not
+// needed anymore, and the statements moved to build().
+func (m *migration) rewriteConstructor(h *stateHolder, text, head string) (string, []string) {
+	prefix := text[:len(text)-len(head)]
+	paren := strings.IndexByte(head, '(')
+	if paren < 0 || strings.HasPrefix(head, "factory ") {
+		return text, nil
+	}
+	params := matchingBracket(head, paren)
+	if params < 0 {
+		return text, nil
+	}
+
+	// The initializer list ends with the body or the semicolon
+	end, depth := params+1, 0
+	for ; end < len(head); end++ {
+		if next := skipDartLiteral(head, end); next != end {
+			end = next - 1
+			continue
+		}
+		c := head[end]
+		if depth == 0 && (c == '{' || c == ';') {
+			break
+		}
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		}
+	}
+	if end >= len(head) {
+		return text, nil
+	}
+	initializers := strings.TrimSpace(head[params+1 : end])
+	if strings.HasPrefix(initializers, ": this(") || strings.HasPrefix(initializers, ":this(") {
+		return text, nil
+	}
+
+	if m.To == Cubit {
+		superCall := " : super(" + h.Initial + ")"
+		if initializers != "" {
+			superCall = ", super(" + h.Initial + ")"
+		}
+		at := params + 1 + len(strings.TrimRight(head[params+1:end], " \t\n"))
+		return prefix + head[:at] + superCall + m.rewriteStateUses(h, head[at:]), nil
+	}
+
+	if head[end] == ';' {
+		if initializers == "" && strings.TrimSpace(head[paren+1:params]) == "" {
+			return "", nil
+		}
+		return text, nil
+	}
+	bodyEnd := matchingBracket(head, end)
+	if bodyEnd < 0 {
+		return text, nil
+	}
+	var statements []string
+	for _, line := range strings.Split(m.rewriteStateUses(h, head[end+1:bodyEnd]), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			statements = append(statements, line)
+		}
+	}
+	if initializers == "" && strings.TrimSpace(head[paren+1:params]) == "" {
+		return "", statements
+	}
+	return prefix + strings.TrimRight(head[:end], " \t\n") + ";", statements
+}
+
+// rewriteStateUses rewrites a member of h to use state instead of the
+// field, without notifyListeners. Cubits emit their new states.
+func (m *migration) rewriteStateUses(h *stateHolder, text string) string {
+	text = regexp.MustCompile(`(?:\bthis\.)?\b`+regexp.QuoteMeta(h.Field)+`\b`).ReplaceAllString(text, "state")
+	text = notifyStatementPattern.ReplaceAllString(text, "")
+	text = notifyCallPattern.ReplaceAllString(text, "")
+	if m.To == Riverpod {
+		if h.Getter == "" {
+			return text
+		}
+		var edits []textEdit
+		for _, use := range identifierUses(text, 0, len(text), h.Getter) {
+			rest := strings.TrimLeft(text[use+len(h.Getter):], " ")
+			if strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "(") {
+				continue
+			}
+			if use > 3 && strings.HasSuffix(strings.TrimRight(text[:use], " "), "get") {
+				continue
+			}
+			edits = append(edits, textEdit{use, use + len(h.Getter), "state"})
+		}
+		return applyEdits(text, edits)
+	}
+
+	var edits []textEdit
+	for _, match := range stateAssignPattern.FindAllStringSubmatchIndex(text, -1) {
+		if match[0] > 0 && text[match[0]-1] == '.' {
+			continue
+		}
+		op := text[match[2]:match[3]]
+		if op == "=" && match[1] < len(text) && (text[match[1]] == '=' || text[match[1]] == '>') {
+			continue
+		}
+		if op == "++" || op == "--" {
+			edits = append(edits, textEdit{match[0], match[1], "emit(state " + op[:1] + " 1)"})
+			continue
+		}
+		end := expressionEnd(text, match[1])
+		value := strings.TrimSpace(text[match[1]:end])
+		switch op {
+		case "=":
+		case "??=":
+			value = "state ?? " + parenthesized(value)
+		default:
+			value = "state " + strings.TrimSuffix(op, "=") + " " + parenthesized(value)
+		}
+		edits = append(edits, textEdit{match[0], end, "emit(" + value + ")"})
+	}
+	for _, match := range statePrefixPattern.FindAllStringSubmatchIndex(text, -1) {
+		edits = append(edits, textEdit{match[0], match[1], "emit(state " + text[match[2]:match[2]+1] + " 1)"})
+	}
+	return applyEdits(text, edits)
+}
+
+func parenthesized(expr string) string {
+	if simpleExpressionPattern.MatchString(expr) {
+		return expr
+	}
+	return "(" + expr + ")"
+}
+
+// fixImports imports the libraries of the target state manager a file now
+// uses, and drops the imports of the source one it does not use anymore.
+func (m *migration) fixImports(file string) {
+	content := m.Files[file]
+	if m.To == Riverpod && riverpodUsePattern.MatchString(content) {
+		content = addDartImport(content, migrationImports[Riverpod])
+	}
+	if m.To == Cubit && (blocUsePattern.MatchString(content) || m.usesContextLookups(content, true)) {
+		content = addDartImport(content, migrationImports[Cubit])
+	}
+
+	source := migrationImports[m.From]
+	if !leftoverPatterns[m.From].MatchString(content) && !m.usesContextLookups(content, m.To == Riverpod) {
+		content = removeDartImport(content, source)
+	}
+	if m.To == Riverpod && strings.Contains(content, "'"+source+"'") && strings.Contains(content, "'"+migrationImports[Riverpod]+"'") {
+		m.note(file, strings.Index(content, source), "imports both %s and flutter_riverpod, which both declare Provider and Consumer: hide them from one of the imports", shortName(m.From))
+	}
+	m.Files[file] = content
+}
+
+// usesContextLookups reports whether a file looks up objects from a
+// BuildContext with the extensions of provider, those of the migrated
+// holders included when migrated is set.
+func (m *migration) usesContextLookups(content string, migrated bool) bool {
+	if m.From != Provider {
+		return false
+	}
+	for _, match := range contextLookupPattern.FindAllStringSubmatch(content, -1) {
+		if migrated || m.holder(match[1]) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// scanLeftovers notes the code of the source state manager left in the
+// files, and the holders created outside their provider.
+func (m *migration) scanLeftovers() {
+	for _, file := range m.sortedFiles() {
+		content := m.Files[file]
+		for _, loc := range leftoverPatterns[m.From].FindAllStringIndex(content, -1) {
+			m.note(file, loc[0], "still uses %s", strings.TrimRight(content[loc[0]:loc[1]], "<"))
+		}
+		if m.To != Riverpod {
+			continue
+		}
+		if m.From == Provider {
+			for _, loc := range contextLookupPattern.FindAllStringSubmatchIndex(content, -1) {
+				m.note(file, loc[0], "still looks up %s from the BuildContext", content[loc[2]:loc[3]])
+			}
+		}
+		for _, h := range m.Holders {
+			if file == h.File {
+				continue
+			}
+			for _, loc := range regexp.MustCompile(`(?:^|[^\w.])`+h.Name+`(?:\.\w+)?\(`).FindAllStringIndex(content, -1) {
+				m.note(file, loc[0]+1, "creates %s directly, read it from %s instead, through a ProviderContainer in tests", h.Name, h.Provider)
+			}
+		}
+	}
+}
+
+// importsSource reports whether a file still imports the source state
+// manager.
+func (m *migration) importsSource() bool {
+	for _, content := range m.Files {
+		if strings.Contains(content, "package:"+stateManagerSpecs[m.From].Packages[0]+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *migration) printReport(dryRun bool) {
+	verb := "Migrated"
+	if dryRun {
+		verb = "Would migrate"
+	}
+	for _, h := range m.Holders {
+		if h.Provider != "" {
+			fmt.Printf("%s %s (%s) to a Notifier read through %s\n", verb, h.Name, h.File, h.Provider)
+		} else {
+			fmt.Printf("%s %s (%s) to a Cubit\n", verb, h.Name, h.File)
+		}
+	}
+	verb = "Updated"
+	if dryRun {
+		verb = "Would update"
+	}
+	for _, file := range m.changedFiles() {
+		fmt.Printf("%s %s\n", verb, file)
+	}
+	m.printNotes()
+}
+
+// printNotes lists the code left to migrate by hand, at its line in the
+// rewritten files.
+func (m *migration) printNotes() {
+	if len(m.Notes) == 0 {
+		return
+	}
+	for i := range m.Notes {
+		note := &m.Notes[i]
+		note.Line = locateLine(m.Files[note.File], note.Snippet, note.Line)
+	}
+	sort.SliceStable(m.Notes, func(i, j int) bool {
+		if m.Notes[i].File != m.Notes[j].File {
+			return m.Notes[i].File < m.Notes[j].File
+		}
+		return m.Notes[i].Line < m.Notes[j].Line
+	})
+	fmt.Printf("\n%d places to migrate by hand:\n", len(m.Notes))
+	for _, note := range m.Notes {
+		fmt.Printf("%s:%d: %s\n    %s\n", note.File, note.Line, note.Message, note.Snippet)
+	}
+}
+
+// locateLine returns the line of content holding snippet closest to line,
+// or line when none does.
+func locateLine(content, snippet string, line int) int {
+	best := line
+	distance := -1
+	for i, text := range strings.Split(content, "\n") {
+		if strings.TrimSpace(text) != snippet {
+			continue
+		}
+		d := i + 1 - line
+		if d < 0 {
+			d = -d
+		}
+		if distance < 0 || d < distance {
+			best, distance = i+1, d
+		}
+	}
+	return best
+}
+
+// updateDependencies adds the packages of the target state manager and
+// removes the source one once nothing imports it.
+func (m *migration) updateDependencies() {
+	cmd1 := exec.Command("flutter", append([]string{"pub", "add"}, stateManagerSpecs[m.To].Packages...)...)
+	cmd1.Dir = m.Root
+	executeCommand(cmd1)
+
+	if !m.importsSource() {
+		cmd2 := exec.Command("flutter", "pub", "remove", stateManagerSpecs[m.From].Packages[0])
+		cmd2.Dir = m.Root
+		executeCommand(cmd2)
+	}
+}
+
+// tidy removes the imports the migration left unused and formats the code.
+func (m *migration) tidy() {
+	cmd1 := exec.Command("dart", "fix", "--apply", "--code=unused_import")
+	cmd1.Dir = m.Root
+	executeCommand(cmd1)
+
+	args := []string{"format"}
+	for _, dir := range []string{"lib", "test"} {
+		if _, err := os.Stat(filepath.Join(m.Root, dir)); err == nil {
+			args = append(args, dir)
+		}
+	}
+	cmd2 := exec.Command("dart", args...)
+	cmd2.Dir = m.Root
+	executeCommand(cmd2)
+}
+
+// updateManifest records the new state manager in the manifest, with the
+// migrated files the user had not changed since they were generated.
+func (m *migration) updateManifest() {
+	manifest, err := readManifest(m.Root)
+	if err != nil {
+		// Only projects generated by the tool have one
+		return
+	}
+	manifest.Options.StateManager = m.To
+	manifest.Architecture = architectureName(manifest.Options.Pattern, m.To)
+	for _, file := range m.changedFiles() {
+		if manifest.Files[file] != hashContent([]byte(m.Original[file])) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(m.Root, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		manifest.Files[file] = hashContent(content)
+		if _, ok := manifest.Base[file]; ok {
+			manifest.Base[file] = string(content)
+		}
+	}
+	if err := saveManifest(m.Root, manifest); err != nil {
+		fmt.Println("Error writing the manifest:", err)
+	}
+}
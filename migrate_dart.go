@@ -0,0 +1,457 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// textEdit replaces the text between Start and End of a file.
+type textEdit struct {
+	Start, End int
+	Text       string
+}
+
+// applyEdits applies the edits to src, skipping the ones overlapping an
+// earlier edit.
+func applyEdits(src string, edits []textEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.Start < last {
+			continue
+		}
+		b.WriteString(src[last:e.Start])
+		b.WriteString(e.Text)
+		last = e.End
+	}
+	b.WriteString(src[last:])
+	return b.String()
+}
+
+// skipDartLiteral returns the index after the comment or string literal
+// starting at i in src, or i when there is none there.
+func skipDartLiteral(src string, i int) int {
+	rest := src[i:]
+	switch {
+	case strings.HasPrefix(rest, "//"):
+		if end := strings.IndexByte(rest, '\n'); end >= 0 {
+			return i + end
+		}
+		return len(src)
+	case strings.HasPrefix(rest, "/*"):
+		if end := strings.Index(rest[2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(src)
+	}
+
+	start, raw := i, false
+	if strings.HasPrefix(rest, "r'") || strings.HasPrefix(rest, `r"`) {
+		if i > 0 && isIdentByte(src[i-1]) {
+			return i
+		}
+		start, raw = i+1, true
+	}
+	if start >= len(src) || (src[start] != '\'' && src[start] != '"') {
+		return i
+	}
+	quote := src[start : start+1]
+	if strings.HasPrefix(src[start:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	for j := start + len(quote); j < len(src); {
+		switch {
+		case strings.HasPrefix(src[j:], quote):
+			return j + len(quote)
+		case src[j] == '\\' && !raw:
+			j += 2
+		case strings.HasPrefix(src[j:], "${") && !raw:
+			end := matchingBracket(src, j+1)
+			if end < 0 {
+				return len(src)
+			}
+			j = end + 1
+		case src[j] == '\n' && len(quote) == 1:
+			// Unterminated string
+			return j
+		default:
+			j++
+		}
+	}
+	return len(src)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// matchingBracket returns the index of the bracket closing the one at open,
+// skipping comments and strings, or -1.
+func matchingBracket(src string, open int) int {
+	depth := 0
+	for i := open; i < len(src); {
+		if next := skipDartLiteral(src, i); next != i {
+			i = next
+			continue
+		}
+		switch src[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// openBrackets returns the brackets still open at pos, outermost first.
+func openBrackets(src string, pos int) []int {
+	var stack []int
+	for i := 0; i < pos; {
+		if next := skipDartLiteral(src, i); next != i {
+			i = next
+			continue
+		}
+		switch src[i] {
+		case '(', '[', '{':
+			stack = append(stack, i)
+		case ')', ']', '}':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		i++
+	}
+	return stack
+}
+
+// expressionEnd returns the end of the expression starting at start: the
+// next comma or semicolon, or the closing bracket of an enclosing bracket.
+func expressionEnd(src string, start int) int {
+	depth := 0
+	for i := start; i < len(src); {
+		if next := skipDartLiteral(src, i); next != i {
+			i = next
+			continue
+		}
+		switch src[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case ',', ';':
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return len(src)
+}
+
+// nextSignificant returns the first character from i that is neither a
+// space nor in a comment, or 0.
+func nextSignificant(src string, i int) byte {
+	for i < len(src) {
+		if isSpaceByte(src[i]) {
+			i++
+			continue
+		}
+		if strings.HasPrefix(src[i:], "//") || strings.HasPrefix(src[i:], "/*") {
+			i = skipDartLiteral(src, i)
+			continue
+		}
+		return src[i]
+	}
+	return 0
+}
+
+// callArg is an argument of a call, or an element of a list literal: Start
+// and End delimit its value, ArgStart and ArgEnd the whole argument.
+type callArg struct {
+	Name             string
+	Start, End       int
+	ArgStart, ArgEnd int
+}
+
+var namedArgPattern = regexp.MustCompile(`^(\w+)\s*:\s*`)
+
+// callArgs splits the arguments between the brackets at open and close.
+func callArgs(src string, open, close int) []callArg {
+	var args []callArg
+	for i := open + 1; i < close; {
+		for i < close && isSpaceByte(src[i]) {
+			i++
+		}
+		if i >= close {
+			break
+		}
+		end := expressionEnd(src, i)
+		if end > close {
+			end = close
+		}
+		arg := callArg{Start: i, End: end, ArgStart: i, ArgEnd: end}
+		if m := namedArgPattern.FindString(src[i:end]); m != "" {
+			arg.Name = strings.TrimSpace(strings.TrimRight(strings.TrimSpace(m), ":"))
+			arg.Start = i + len(m)
+		}
+		for arg.End > arg.Start && isSpaceByte(src[arg.End-1]) {
+			arg.End--
+		}
+		args = append(args, arg)
+		i = end + 1
+	}
+	return args
+}
+
+// namedArg returns the argument called name.
+func namedArg(args []callArg, name string) (callArg, bool) {
+	for _, arg := range args {
+		if arg.Name == name {
+			return arg, true
+		}
+	}
+	return callArg{}, false
+}
+
+// wholeLines extends the range to the lines holding it when nothing else is
+// on them, so that removing it leaves no blank line behind.
+func wholeLines(src string, start, end int) (int, int) {
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	if strings.TrimSpace(src[lineStart:start]) != "" {
+		return start, end
+	}
+	lineEnd := end
+	for lineEnd < len(src) && (src[lineEnd] == ' ' || src[lineEnd] == '\t') {
+		lineEnd++
+	}
+	if lineEnd < len(src) && src[lineEnd] != '\n' {
+		return start, end
+	}
+	if lineEnd < len(src) {
+		lineEnd++
+	}
+	return lineStart, lineEnd
+}
+
+// lineOf returns the 1-based line of pos in src.
+func lineOf(src string, pos int) int {
+	return strings.Count(src[:pos], "\n") + 1
+}
+
+// lineText returns the trimmed line holding pos.
+func lineText(src string, pos int) string {
+	start := strings.LastIndexByte(src[:pos], '\n') + 1
+	end := strings.IndexByte(src[pos:], '\n')
+	if end < 0 {
+		return strings.TrimSpace(src[start:])
+	}
+	return strings.TrimSpace(src[start : pos+end])
+}
+
+// classMember is a declaration in the body of a class.
+type classMember struct {
+	Start, End int
+	// Head is the declaration without its comments and annotations
+	Head string
+}
+
+// classMembers splits the body of the class between the braces at open and
+// close into its declarations.
+func classMembers(src string, open, close int) []classMember {
+	var members []classMember
+	i := open + 1
+	for {
+		for i < close && isSpaceByte(src[i]) {
+			i++
+		}
+		if i >= close {
+			return members
+		}
+		start, end, depth := i, close, 0
+	scan:
+		for i < close {
+			if next := skipDartLiteral(src, i); next != i {
+				i = next
+				continue
+			}
+			switch src[i] {
+			case '(', '[', '{':
+				depth++
+			case ')', ']':
+				depth--
+			case '}':
+				depth--
+				// A body ends the declaration, a literal is followed by
+				// the rest of the expression
+				if depth == 0 && !strings.ContainsRune(";.,?", rune(nextSignificant(src, i+1))) {
+					end = i + 1
+					i++
+					break scan
+				}
+			case ';':
+				if depth == 0 {
+					end = i + 1
+					i++
+					break scan
+				}
+			}
+			i++
+		}
+		members = append(members, classMember{Start: start, End: end, Head: memberHead(src[start:end])})
+	}
+}
+
+var annotationPattern = regexp.MustCompile(`^@[\w.]+`)
+
+// memberHead strips the comments and annotations leading a declaration.
+func memberHead(text string) string {
+	for {
+		text = strings.TrimSpace(text)
+		switch {
+		case strings.HasPrefix(text, "//"), strings.HasPrefix(text, "/*"):
+			text = text[skipDartLiteral(text, 0):]
+		case annotationPattern.MatchString(text):
+			end := len(annotationPattern.FindString(text))
+			if end < len(text) && text[end] == '(' {
+				if close := matchingBracket(text, end); close >= 0 {
+					end = close + 1
+				}
+			}
+			text = text[end:]
+		default:
+			return text
+		}
+	}
+}
+
+// Kinds of class members
+const (
+	fieldMember  = "field"
+	getterMember = "getter"
+	setterMember = "setter"
+	ctorMember   = "constructor"
+	methodMember = "method"
+)
+
+var (
+	getterPattern = regexp.MustCompile(`^(?:static\s+)?([\w<>?,\s\[\]]+?)\s+get\s+(\w+)\s*(?:=>\s*([\s\S]*?)\s*;|\{)`)
+	setterPattern = regexp.MustCompile(`^(?:static\s+)?(?:void\s+)?set\s+(\w+)\s*\(`)
+	fieldPattern  = regexp.MustCompile(`^(static\s+)?(late\s+)?(final\s+|const\s+|var\s+)?([\w<>?,\s\[\]]+?\s+)?(\w+)\s*(?:=\s*([\s\S]*?))?\s*;$`)
+	namePattern   = regexp.MustCompile(`([\w.]+)\s*(?:<[^()]*>)?\s*$`)
+)
+
+// memberInfo describes a declaration of a class called class.
+type memberInfo struct {
+	Kind string
+	Name string
+	// Type, Body and Init are set for fields and getters
+	Type string
+	Body string
+	Init string
+	// Mutable is set for fields that are neither final, const nor static
+	Mutable bool
+}
+
+func describeMember(class, head string) memberInfo {
+	if m := getterPattern.FindStringSubmatch(head); m != nil {
+		return memberInfo{Kind: getterMember, Name: m[2], Type: strings.TrimSpace(m[1]), Body: m[3]}
+	}
+	if m := setterPattern.FindStringSubmatch(head); m != nil {
+		return memberInfo{Kind: setterMember, Name: m[1]}
+	}
+	paren, assign := strings.IndexByte(head, '('), strings.IndexByte(head, '=')
+	if paren >= 0 && (assign < 0 || paren < assign) {
+		name := namePattern.FindStringSubmatch(head[:paren])
+		if name == nil {
+			return memberInfo{Kind: methodMember}
+		}
+		if name[1] == class || strings.HasPrefix(name[1], class+".") || strings.HasPrefix(head, "factory ") {
+			return memberInfo{Kind: ctorMember, Name: name[1]}
+		}
+		return memberInfo{Kind: methodMember, Name: name[1]}
+	}
+	if m := fieldPattern.FindStringSubmatch(head); m != nil {
+		modifier := strings.TrimSpace(m[3])
+		return memberInfo{
+			Kind:    fieldMember,
+			Name:    m[5],
+			Type:    strings.TrimSpace(m[4]),
+			Init:    strings.TrimSpace(m[6]),
+			Mutable: m[1] == "" && modifier != "final" && modifier != "const",
+		}
+	}
+	return memberInfo{}
+}
+
+// dartIdentifier matches name as a whole identifier at i of src.
+func dartIdentifierAt(src string, i int, name string) bool {
+	if !strings.HasPrefix(src[i:], name) {
+		return false
+	}
+	if i > 0 && isIdentByte(src[i-1]) {
+		return false
+	}
+	end := i + len(name)
+	return end >= len(src) || !isIdentByte(src[end])
+}
+
+// identifierUses returns the positions of name used as an identifier in
+// src between start and end, leaving out the members of other objects.
+func identifierUses(src string, start, end int, name string) []int {
+	var uses []int
+	for i := start; i < end; {
+		at := strings.Index(src[i:end], name)
+		if at < 0 {
+			break
+		}
+		at += i
+		// Members are preceded by a dot, spread collections by three
+		if dartIdentifierAt(src, at, name) && (at == 0 || src[at-1] != '.' || strings.HasSuffix(src[:at], "...")) {
+			uses = append(uses, at)
+		}
+		i = at + len(name)
+	}
+	return uses
+}
+
+// addDartImport adds an import of uri to a Dart file that does not have one.
+func addDartImport(content, uri string) string {
+	for _, match := range dartDirectivePattern.FindAllStringSubmatch(content, -1) {
+		if match[1] == "import" && match[2] == uri {
+			return content
+		}
+	}
+	return sortDartImports("import '" + uri + "';\n" + content)
+}
+
+// addDartDirective adds an import directive, which may have a prefix or
+// combinators, to a Dart file that does not import its URI yet.
+func addDartDirective(content, directive string) string {
+	uri := importURI(directive)
+	for _, match := range dartDirectivePattern.FindAllStringSubmatch(content, -1) {
+		if match[1] == "import" && match[2] == uri {
+			return content
+		}
+	}
+	return sortDartImports(directive + "\n" + content)
+}
+
+// removeDartImport removes the import of uri from a Dart file.
+func removeDartImport(content, uri string) string {
+	pattern := regexp.MustCompile(`(?m)^import\s+['"]` + regexp.QuoteMeta(uri) + `['"][^;]*;[ \t]*\n?`)
+	return pattern.ReplaceAllString(content, "")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+// consumerTests are tests reading the sample holder from the widget tree,
+// like the ones of a project would. {{holder}} is the holder, {{uri}} its URI.
+var consumerTests = map[string]string{
+	Provider: `import '{{uri}}';
+import 'package:flutter/material.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:provider/provider.dart';
+
+class CountText extends StatelessWidget {
+  const CountText({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Text('${context.watch<{{holder}}>().count}');
+  }
+}
+
+void main() {
+  test('CountText is a widget', () {
+    expect(const CountText(), isA<Widget>());
+  });
+}
+`,
+	ScopedModel: `import '{{uri}}';
+import 'package:flutter/material.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:scoped_model/scoped_model.dart';
+
+class CountText extends StatelessWidget {
+  const CountText({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return ScopedModelDescendant<{{holder}}>(
+      builder: (context, child, model) => Text('${model.count}'),
+    );
+  }
+}
+
+void main() {
+  test('CountText is a widget', () {
+    expect(const CountText(), isA<Widget>());
+  });
+}
+`,
+}
+
+func TestMigrateGeneratedSamples(t *testing.T) {
+	for _, source := range []string{Provider, ScopedModel} {
+		for _, pattern := range patterns {
+			for _, to := range []string{"riverpod", "cubit", "bloc"} {
+				t.Run(shortName(source)+"/"+pattern+"/"+to, func(t *testing.T) {
+					target := migrateTargets[to]
+					opts := projectOptions{ProjectName: "demo", Pattern: pattern, StateManager: source}
+					rendered, err := renderArchitecture("demo", opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+					data, err := newSampleData("demo", opts, stateManagerSpecs[source])
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					root := t.TempDir()
+					files := map[string]string{
+						"pubspec.yaml":         "name: demo\n",
+						"test/count_test.dart": strings.NewReplacer("{{holder}}", data.Holder, "{{uri}}", data.HolderURI).Replace(consumerTests[source]),
+					}
+					for _, file := range rendered {
+						files[file.Path] = file.Content
+					}
+					writeTestFiles(t, root, files)
+
+					m, err := newMigration(root, source, target)
+					if err != nil {
+						t.Fatal(err)
+					}
+					m.run()
+					if len(m.Holders) == 0 {
+						t.Fatal("no state holder was migrated")
+					}
+					if m.Files["test/count_test.dart"] == m.Original["test/count_test.dart"] {
+						t.Error("test/count_test.dart was not migrated")
+					}
+
+					for _, file := range m.sortedFiles() {
+						content := m.Files[file]
+						if strings.Contains(content, "'"+migrationImports[source]+"'") {
+							t.Errorf("%s still imports %s:\n%s", file, migrationImports[source], content)
+						}
+						uses := riverpodUsePattern.MatchString(content)
+						if target == Cubit {
+							uses = blocUsePattern.MatchString(content) || strings.Contains(content, "context.watch<")
+						}
+						if uses && !strings.Contains(content, "'"+migrationImports[target]+"'") {
+							t.Errorf("%s does not import %s:\n%s", file, migrationImports[target], content)
+						}
+						for _, match := range importDirectivePattern.FindAllStringSubmatch(content, -1) {
+							if uri := strings.TrimPrefix(match[1], "package:demo/"); uri != match[1] {
+								if _, ok := m.Files[path.Join("lib", uri)]; !ok {
+									t.Errorf("%s imports %s, which does not exist", file, match[1])
+								}
+							}
+						}
+					}
+				})
+			}
+		}
+	}
+}
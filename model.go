@@ -0,0 +1,796 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Model styles
+const (
+	PlainModel            string = "plain"
+	JSONSerializableModel string = "json_serializable"
+	FreezedModel          string = "freezed"
+)
+
+// jsonObject is a JSON object keeping the order of its keys, so that the
+// fields of the generated classes follow the payload.
+type jsonObject struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// dartField is a field of a generated Dart class.
+type dartField struct {
+	Key  string
+	Name string
+	Type string
+	// Class is the generated class of an object, or of the elements of a
+	// list of objects
+	Class string
+	// List is the number of lists nested around the values, 0 for a single
+	// value
+	List int
+	// NullableElements is set when the innermost lists hold nulls
+	NullableElements bool
+	Nullable         bool
+}
+
+// dartClass is a Dart class generated from a JSON object.
+type dartClass struct {
+	Name   string
+	Fields []dartField
+}
+
+// dartReservedWords cannot be used as field names.
+var dartReservedWords = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "default": true, "do": true, "else": true,
+	"enum": true, "extends": true, "false": true, "final": true, "finally": true,
+	"for": true, "if": true, "in": true, "is": true, "new": true, "null": true,
+	"rethrow": true, "return": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "var": true, "void": true,
+	"while": true, "with": true,
+}
+
+func modelCommand(args []string) {
+	fs := flag.NewFlagSet("model", flag.ExitOnError)
+	style := fs.String("style", "", "model style: plain, json_serializable or freezed (detected from pubspec.yaml by default)")
+	feature := fs.String("feature", "", "feature the model belongs to, for Clean Architecture projects")
+	project := fs.String("project", ".", "path of the Flutter project")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: flutter-arch model [flags] <json file> <ClassName>")
+		fs.PrintDefaults()
+	}
+	positional := parseInterspersed(fs, args)
+	if len(positional) != 2 {
+		fs.Usage()
+		return
+	}
+	jsonPath, className := positional[0], positional[1]
+	if !isDartClassName(className) {
+		className = dartClassName(className)
+	}
+
+	content, err := os.ReadFile(jsonPath)
+	if err != nil {
+		fmt.Println("Error reading JSON file:", err)
+		return
+	}
+	classes, err := dartClassesFromJSON(className, content)
+	if err != nil {
+		fmt.Println("Error parsing JSON file:", err)
+		return
+	}
+
+	projectPath := *project
+	if *style == "" {
+		*style = detectModelStyle(projectPath)
+	}
+	switch *style {
+	case PlainModel, JSONSerializableModel, FreezedModel:
+	default:
+		fmt.Printf("Model style %s is not supported.\n", *style)
+		return
+	}
+
+	modelsDir, err := modelsDirectory(projectPath, *feature)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Add necessary packages for the model style
+	addModelPackages(projectPath, *style)
+
+	fileName := snakeCase(className) + ".dart"
+	filePath := filepath.Join(modelsDir, fileName)
+	os.MkdirAll(modelsDir, 0755)
+	createFile(filePath, renderDartModel(classes, *style, fileName, nil))
+	fmt.Printf("Model %s created in %s\n", className, filePath)
+
+	if *style != PlainModel {
+		cmd1 := exec.Command("dart", "run", "build_runner", "build", "--delete-conflicting-outputs")
+		cmd1.Dir = projectPath
+		executeCommand(cmd1)
+	}
+
+	cmd2 := exec.Command("dart", "format", filePath)
+	executeCommand(cmd2)
+}
+
+// parseInterspersed parses flags placed before, between or after the
+// positional arguments and returns the latter.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// detectModelStyle picks the model style from the code generators the
+// project already depends on.
+func detectModelStyle(projectPath string) string {
+	style := PlainModel
+	for _, dep := range pubspecDependencies(projectPath, "dev_dependencies") {
+		switch dep {
+		case "freezed":
+			return FreezedModel
+		case "json_serializable":
+			style = JSONSerializableModel
+		}
+	}
+	return style
+}
+
+// modelsDirectory returns the folder the architecture of the project keeps
+// its models in: the data layer of a feature for Clean Architecture, model/
+// for MVVM and MVC and models/ otherwise.
+func modelsDirectory(projectPath, feature string) (string, error) {
+	lib := filepath.Join(projectPath, "lib")
+
+	featuresDir := filepath.Join(lib, "features")
+	if entries, err := os.ReadDir(featuresDir); err == nil {
+		if feature == "" {
+			var features []string
+			for _, entry := range entries {
+				if entry.IsDir() {
+					features = append(features, entry.Name())
+				}
+			}
+			if len(features) != 1 {
+				return "", fmt.Errorf("choose the feature of the model with --feature, one of: %s", strings.Join(features, ", "))
+			}
+			feature = features[0]
+		}
+		return filepath.Join(featuresDir, snakeCase(feature), "data", "models"), nil
+	}
+
+	if info, err := os.Stat(filepath.Join(lib, "model")); err == nil && info.IsDir() {
+		return filepath.Join(lib, "model"), nil
+	}
+	return filepath.Join(lib, "models"), nil
+}
+
+func addModelPackages(projectPath, style string) {
+	var packages, devPackages []string
+	switch style {
+	case JSONSerializableModel:
+		packages = []string{"json_annotation"}
+		devPackages = []string{"build_runner", "json_serializable"}
+	case FreezedModel:
+		packages = freezedPackages
+		devPackages = freezedDevPackages
+	}
+
+	existing := make(map[string]bool)
+	for _, section := range []string{"dependencies", "dev_dependencies"} {
+		for _, dep := range pubspecDependencies(projectPath, section) {
+			existing[dep] = true
+		}
+	}
+
+	var missing []string
+	for _, pkg := range packages {
+		if !existing[pkg] {
+			missing = append(missing, pkg)
+		}
+	}
+	for _, pkg := range devPackages {
+		if !existing[pkg] {
+			missing = append(missing, "dev:"+pkg)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	cmd := exec.Command("flutter", append([]string{"pub", "add"}, missing...)...)
+	cmd.Dir = projectPath
+	executeCommand(cmd)
+}
+
+// dartClassesFromJSON returns the root class of the payload followed by the
+// classes of its nested objects. A payload holding a list, and the lists of
+// objects it holds, are modeled after all their elements.
+func dartClassesFromJSON(className string, content []byte) ([]dartClass, error) {
+	dec := json.NewDecoder(strings.NewReader(string(content)))
+	dec.UseNumber()
+	value, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	samples := []interface{}{value}
+	if list, ok := value.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil, fmt.Errorf("the payload is an empty list")
+		}
+		samples = list
+	}
+	objects, ok := jsonObjects(samples)
+	if !ok {
+		return nil, fmt.Errorf("the payload is not an object")
+	}
+
+	var classes []dartClass
+	used := make(map[string]bool)
+	var addClass func(name string, objects []*jsonObject)
+	addClass = func(name string, objects []*jsonObject) {
+		used[name] = true
+		index := len(classes)
+		classes = append(classes, dartClass{Name: name})
+
+		keys, values := mergeJSONObjects(objects)
+		names := make(map[string]bool)
+		var fields []dartField
+		for _, key := range keys {
+			field := dartField{Key: key, Name: uniqueFieldName(dartFieldName(key), names)}
+			// A key missing from an object or null in one is optional
+			samples, hasNull := withoutNulls(values[key])
+			field.Nullable = hasNull || len(values[key]) < len(objects)
+
+			// The field holds the elements of all its lists
+			for {
+				lists, ok := jsonLists(samples)
+				if !ok {
+					break
+				}
+				field.List++
+				samples = nil
+				field.NullableElements = false
+				for _, list := range lists {
+					elements, hasNull := withoutNulls(list)
+					samples = append(samples, elements...)
+					field.NullableElements = field.NullableElements || hasNull
+				}
+			}
+
+			if objects, ok := jsonObjects(samples); ok {
+				className := key
+				if field.List > 0 {
+					className = singular(key)
+				}
+				field.Class = uniqueClassName(dartClassName(className), name, used)
+				field.Type = field.Class
+				addClass(field.Class, objects)
+			} else if len(samples) == 0 && field.List == 0 {
+				// Only nulls, the type is unknown
+				field.Type = "Object"
+				field.Nullable = true
+			} else {
+				field.Type = jsonScalarType(samples)
+				field.NullableElements = field.NullableElements && field.Type != "dynamic"
+			}
+			fields = append(fields, field)
+		}
+		classes[index].Fields = fields
+	}
+	addClass(className, objects)
+	return classes, nil
+}
+
+// mergeJSONObjects returns the keys of the objects, in the order they first
+// appear, and the values each key has in the objects holding it.
+func mergeJSONObjects(objects []*jsonObject) ([]string, map[string][]interface{}) {
+	var keys []string
+	values := make(map[string][]interface{})
+	for _, object := range objects {
+		for _, key := range object.Keys {
+			if _, ok := values[key]; !ok {
+				keys = append(keys, key)
+			}
+			values[key] = append(values[key], object.Values[key])
+		}
+	}
+	return keys, values
+}
+
+// withoutNulls drops the null values and reports whether there were any.
+func withoutNulls(values []interface{}) ([]interface{}, bool) {
+	var result []interface{}
+	for _, value := range values {
+		if value != nil {
+			result = append(result, value)
+		}
+	}
+	return result, len(result) < len(values)
+}
+
+// jsonObjects returns the values when all of them are objects.
+func jsonObjects(values []interface{}) ([]*jsonObject, bool) {
+	var objects []*jsonObject
+	for _, value := range values {
+		object, ok := value.(*jsonObject)
+		if !ok {
+			return nil, false
+		}
+		objects = append(objects, object)
+	}
+	return objects, len(objects) > 0
+}
+
+// jsonLists returns the values when all of them are lists.
+func jsonLists(values []interface{}) ([][]interface{}, bool) {
+	var lists [][]interface{}
+	for _, value := range values {
+		list, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		lists = append(lists, list)
+	}
+	return lists, len(lists) > 0
+}
+
+// jsonScalarType returns the Dart type of values that are not objects nor
+// lists: dynamic when there are none, Object when their types differ.
+func jsonScalarType(values []interface{}) string {
+	dartType := ""
+	for _, value := range values {
+		var t string
+		switch v := value.(type) {
+		case json.Number:
+			t = "int"
+			if strings.ContainsAny(v.String(), ".eE") {
+				t = "double"
+			}
+		case string:
+			t = "String"
+		case bool:
+			t = "bool"
+		default:
+			return "Object"
+		}
+		switch {
+		case dartType == "" || dartType == t:
+			dartType = t
+		case dartType == "int" && t == "double", dartType == "double" && t == "int":
+			dartType = "double"
+		default:
+			return "Object"
+		}
+	}
+	if dartType == "" {
+		return "dynamic"
+	}
+	return dartType
+}
+
+// decodeJSONValue decodes the next JSON value, keeping the order of the keys
+// of objects.
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err == io.EOF {
+		return nil, fmt.Errorf("unexpected end of JSON input")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := &jsonObject{Values: make(map[string]interface{})}
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := object.Values[key]; !ok {
+				object.Keys = append(object.Keys, key)
+			}
+			object.Values[key] = value
+		}
+		_, err := dec.Token()
+		return object, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return token, nil
+}
+
+// uniqueClassName returns name, prefixed with its parent class when it is
+// already taken.
+func uniqueClassName(name, parent string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	base := parent + name
+	name = base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	return name
+}
+
+// uniqueFieldName returns name, suffixed with a number when another key of
+// the class already maps to it. The field then keeps its key with @JsonKey.
+func uniqueFieldName(name string, used map[string]bool) string {
+	base := name
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+// jsonKeyWords splits a JSON key into words, on separators and case changes.
+func jsonKeyWords(key string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(key)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// dartClassName converts a JSON key to an UpperCamelCase class name.
+func dartClassName(key string) string {
+	var b strings.Builder
+	for _, word := range jsonKeyWords(key) {
+		b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Model" + name
+	}
+	return name
+}
+
+// singular returns the singular of an English plural, naming the elements of
+// a list.
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && len(word) > 1:
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// isDartClassName reports whether name is already an UpperCamelCase class
+// name.
+func isDartClassName(name string) bool {
+	if name == "" || !unicode.IsUpper(rune(name[0])) {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// dartFieldName converts a JSON key to a lowerCamelCase field name.
+func dartFieldName(key string) string {
+	var b strings.Builder
+	for i, word := range jsonKeyWords(key) {
+		if i == 0 {
+			b.WriteString(strings.ToLower(word))
+		} else {
+			b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+		}
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "field" + name
+	}
+	if dartReservedWords[name] {
+		name += "Value"
+	}
+	return name
+}
+
+// dartType returns the Dart type of a field.
+func (f dartField) dartType() string {
+	t := f.Type
+	if f.NullableElements {
+		t += "?"
+	}
+	for i := 0; i < f.List; i++ {
+		t = "List<" + t + ">"
+	}
+	if f.Nullable {
+		t += "?"
+	}
+	return t
+}
+
+// renderDartModel renders the classes of a model file in the given style.
+// imports are the URIs of the other models the classes reference.
+func renderDartModel(classes []dartClass, style, fileName string, imports []string) string {
+	base := strings.TrimSuffix(fileName, ".dart")
+	var b strings.Builder
+	for _, uri := range imports {
+		fmt.Fprintf(&b, "import '%s';\n", uri)
+	}
+	switch style {
+	case JSONSerializableModel:
+		fmt.Fprintf(&b, "import 'package:json_annotation/json_annotation.dart';\n\npart '%s.g.dart';\n", base)
+	case FreezedModel:
+		fmt.Fprintf(&b, "import 'package:freezed_annotation/freezed_annotation.dart';\n\npart '%s.freezed.dart';\npart '%s.g.dart';\n", base, base)
+	}
+
+	for _, class := range classes {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		switch style {
+		case JSONSerializableModel:
+			writeJSONSerializableClass(&b, class)
+		case FreezedModel:
+			writeFreezedClass(&b, class)
+		default:
+			writePlainClass(&b, class)
+		}
+	}
+	return sortDartImports(b.String())
+}
+
+// constructorParams returns the named parameters of a class constructor, one
+// per line.
+// Required parameters come first, as always_put_required_named_parameters_first
+// wants.
+func constructorParams(class dartClass, param func(dartField) string) string {
+	var b strings.Builder
+	for _, nullable := range []bool{false, true} {
+		for _, field := range class.Fields {
+			if field.Nullable == nullable {
+				fmt.Fprintf(&b, "    %s,\n", param(field))
+			}
+		}
+	}
+	return b.String()
+}
+
+func hasNestedClasses(class dartClass) bool {
+	for _, field := range class.Fields {
+		if field.Class != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func writePlainClass(b *strings.Builder, class dartClass) {
+	fmt.Fprintf(b, "class %s {\n", class.Name)
+	if len(class.Fields) == 0 {
+		fmt.Fprintf(b, "  const %s();\n\n", class.Name)
+		fmt.Fprintf(b, "  factory %s.fromJson(Map<String, dynamic> _) => const %s();\n\n", class.Name, class.Name)
+		b.WriteString("  Map<String, dynamic> toJson() => {};\n}\n")
+		return
+	}
+
+	fmt.Fprintf(b, "  const %s({\n%s  });\n\n", class.Name, constructorParams(class, func(f dartField) string {
+		if f.Nullable {
+			return "this." + f.Name
+		}
+		return "required this." + f.Name
+	}))
+
+	fmt.Fprintf(b, "  factory %s.fromJson(Map<String, dynamic> json) {\n    return %s(\n", class.Name, class.Name)
+	for _, field := range class.Fields {
+		fmt.Fprintf(b, "      %s: %s,\n", field.Name, plainFromJSON(field, "json["+dartString(field.Key)+"]"))
+	}
+	b.WriteString("    );\n  }\n\n")
+
+	for _, field := range class.Fields {
+		fmt.Fprintf(b, "  final %s %s;\n", field.dartType(), field.Name)
+	}
+
+	b.WriteString("\n  Map<String, dynamic> toJson() {\n    return {\n")
+	for _, field := range class.Fields {
+		fmt.Fprintf(b, "      %s: %s,\n", dartString(field.Key), plainToJSON(field))
+	}
+	b.WriteString("    };\n  }\n}\n")
+}
+
+// plainFromJSON returns the expression reading a field from its JSON value.
+func plainFromJSON(field dartField, value string) string {
+	element := func(value string, nullable bool) string {
+		q := ""
+		if nullable {
+			q = "?"
+		}
+		switch {
+		case field.Class != "" && nullable:
+			return fmt.Sprintf("%s == null ? null : %s.fromJson(%s as Map<String, dynamic>)", value, field.Class, value)
+		case field.Class != "":
+			return fmt.Sprintf("%s.fromJson(%s as Map<String, dynamic>)", field.Class, value)
+		case field.Type == "DateTime" && nullable:
+			return fmt.Sprintf("%s == null ? null : DateTime.parse(%s as String)", value, value)
+		case field.Type == "DateTime":
+			return fmt.Sprintf("DateTime.parse(%s as String)", value)
+		case field.Type == "double":
+			return fmt.Sprintf("(%s as num%s)%s.toDouble()", value, q, q)
+		case field.Type == "dynamic" || field.Type == "Object" && nullable:
+			return value
+		default:
+			return fmt.Sprintf("%s as %s%s", value, field.Type, q)
+		}
+	}
+	var list func(value string, level int, nullable bool) string
+	list = func(value string, level int, nullable bool) string {
+		if level == field.List {
+			return element(value, field.NullableElements)
+		}
+		q := ""
+		if nullable {
+			q = "?"
+		}
+		if field.Type == "dynamic" && level == field.List-1 {
+			return value + " as List<dynamic>" + q
+		}
+		e := listElementVar(level)
+		return fmt.Sprintf("(%s as List<dynamic>%s)%s.map((%s) => %s).toList()", value, q, q, e, list(e, level+1, false))
+	}
+	if field.List > 0 {
+		return list(value, 0, field.Nullable)
+	}
+	return element(value, field.Nullable)
+}
+
+// listElementVar names the elements of the lists nested level times in a
+// field, e, e2, e3...
+func listElementVar(level int) string {
+	if level == 0 {
+		return "e"
+	}
+	return fmt.Sprintf("e%d", level+1)
+}
+
+// plainToJSON returns the expression writing a field to JSON.
+func plainToJSON(field dartField) string {
+	var convert string
+	switch {
+	case field.Class != "":
+		convert = "toJson()"
+	case field.Type == "DateTime":
+		convert = "toIso8601String()"
+	default:
+		return field.Name
+	}
+	access := "."
+	if field.Nullable {
+		access = "?."
+	}
+	var list func(value, access string, level int) string
+	list = func(value, access string, level int) string {
+		if level == field.List {
+			if field.NullableElements {
+				access = "?."
+			}
+			return value + access + convert
+		}
+		e := listElementVar(level)
+		return value + access + "map((" + e + ") => " + list(e, ".", level+1) + ").toList()"
+	}
+	return list(field.Name, access, 0)
+}
+
+// jsonKeyAnnotation returns the annotation mapping a field to its JSON key
+// when their names differ.
+func jsonKeyAnnotation(field dartField) string {
+	if field.Name == field.Key {
+		return ""
+	}
+	return "@JsonKey(name: " + dartString(field.Key) + ") "
+}
+
+func writeJSONSerializableClass(b *strings.Builder, class dartClass) {
+	if hasNestedClasses(class) {
+		b.WriteString("@JsonSerializable(explicitToJson: true)\n")
+	} else {
+		b.WriteString("@JsonSerializable()\n")
+	}
+	fmt.Fprintf(b, "class %s {\n", class.Name)
+	if len(class.Fields) == 0 {
+		fmt.Fprintf(b, "  const %s();\n\n", class.Name)
+	} else {
+		fmt.Fprintf(b, "  const %s({\n%s  });\n\n", class.Name, constructorParams(class, func(f dartField) string {
+			if f.Nullable {
+				return "this." + f.Name
+			}
+			return "required this." + f.Name
+		}))
+	}
+	fmt.Fprintf(b, "  factory %s.fromJson(Map<String, dynamic> json) =>\n      _$%sFromJson(json);\n\n", class.Name, class.Name)
+	for _, field := range class.Fields {
+		if annotation := jsonKeyAnnotation(field); annotation != "" {
+			fmt.Fprintf(b, "  %s\n", strings.TrimSpace(annotation))
+		}
+		fmt.Fprintf(b, "  final %s %s;\n", field.dartType(), field.Name)
+	}
+	if len(class.Fields) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(b, "  Map<String, dynamic> toJson() => _$%sToJson(this);\n}\n", class.Name)
+}
+
+func writeFreezedClass(b *strings.Builder, class dartClass) {
+	b.WriteString("@freezed\n")
+	fmt.Fprintf(b, "abstract class %s with _$%s {\n", class.Name, class.Name)
+	// Same as json_serializable, nested classes are written as maps
+	if hasNestedClasses(class) {
+		b.WriteString("  @JsonSerializable(explicitToJson: true)\n")
+	}
+	if len(class.Fields) == 0 {
+		fmt.Fprintf(b, "  const factory %s() = _%s;\n\n", class.Name, class.Name)
+	} else {
+		fmt.Fprintf(b, "  const factory %s({\n%s  }) = _%s;\n\n", class.Name, constructorParams(class, func(f dartField) string {
+			param := jsonKeyAnnotation(f)
+			if !f.Nullable {
+				param += "required "
+			}
+			return param + f.dartType() + " " + f.Name
+		}), class.Name)
+	}
+	fmt.Fprintf(b, "  factory %s.fromJson(Map<String, dynamic> json) =>\n      _$%sFromJson(json);\n}\n", class.Name, class.Name)
+}
+
+// dartString quotes a JSON key as a Dart string literal.
+func dartString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`, "$", `\$`).Replace(s) + "'"
+}
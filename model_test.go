@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// describeClasses lists the classes as "Class{type name, ...}", adding the
+// key of the fields named after another one.
+func describeClasses(classes []dartClass) string {
+	var parts []string
+	for _, class := range classes {
+		var fields []string
+		for _, field := range class.Fields {
+			desc := field.dartType() + " " + field.Name
+			if field.Name != dartFieldName(field.Key) {
+				desc += "@" + field.Key
+			}
+			fields = append(fields, desc)
+		}
+		parts = append(parts, class.Name+"{"+strings.Join(fields, ", ")+"}")
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestDartClassesFromJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{
+			name:    "scalars",
+			payload: `{"id": 1, "price": 2.5, "name": "a", "active": true, "note": null}`,
+			want:    "User{int id, double price, String name, bool active, Object? note}",
+		},
+		{
+			name:    "nested lists",
+			payload: `{"matrix": [[1, 2], [3]], "tags": [], "grid": [[]]}`,
+			want:    "User{List<List<int>> matrix, List<dynamic> tags, List<List<dynamic>> grid}",
+		},
+		{
+			name:    "nested lists of objects",
+			payload: `{"rows": [[{"x": 1}], [{"x": 2, "y": "b"}]]}`,
+			want:    "User{List<List<Row>> rows} Row{int x, String? y}",
+		},
+		{
+			name:    "keys mapping to the same name",
+			payload: `{"user_id": 1, "userId": 2, "UserID": 3}`,
+			want:    "User{int userId, int userId2@userId, int userId3@UserID}",
+		},
+		{
+			name:    "list elements merged",
+			payload: `{"items": [{"id": 1, "label": "a"}, {"id": 2.5, "label": null, "extra": true}]}`,
+			want:    "User{List<Item> items} Item{double id, String? label, bool? extra}",
+		},
+		{
+			name:    "root list merged",
+			payload: `[{"id": 1}, {"id": 2, "name": "b"}, {"id": 3, "name": 4}]`,
+			want:    "User{int id, Object? name}",
+		},
+		{
+			name:    "mixed types",
+			payload: `{"value": [1, "a"], "maybe": [null, 1]}`,
+			want:    "User{List<Object> value, List<int?> maybe}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classes, err := dartClassesFromJSON("User", []byte(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describeClasses(classes); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDartClassesFromJSONErrors(t *testing.T) {
+	for _, payload := range []string{`[]`, `[[1]]`, `1`, `{"a": `} {
+		if _, err := dartClassesFromJSON("User", []byte(payload)); err == nil {
+			t.Errorf("%s: expected an error", payload)
+		}
+	}
+}
+
+func TestRenderPlainNestedLists(t *testing.T) {
+	classes, err := dartClassesFromJSON("Board", []byte(`{"cells": [[{"x": 1}]], "user_id": 1, "userId": 2, "scores": [1, null], "any": [1, "a"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := renderDartModel(classes, PlainModel, "board.dart", nil)
+	for _, want := range []string{
+		"final List<List<Cell>> cells;",
+		"cells: (json['cells'] as List<dynamic>).map((e) => (e as List<dynamic>).map((e2) => Cell.fromJson(e2 as Map<String, dynamic>)).toList()).toList(),",
+		"'cells': cells.map((e) => e.map((e2) => e2.toJson()).toList()).toList(),",
+		"userId2: json['userId'] as int,",
+		"'userId': userId2,",
+		"scores: (json['scores'] as List<dynamic>).map((e) => e as int?).toList(),",
+		"any: (json['any'] as List<dynamic>).map((e) => e as Object).toList(),",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderSerializableDuplicateKeys(t *testing.T) {
+	classes, err := dartClassesFromJSON("User", []byte(`{"user_id": 1, "userId": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range []string{JSONSerializableModel, FreezedModel} {
+		got := renderDartModel(classes, style, "user.dart", nil)
+		if !strings.Contains(got, "@JsonKey(name: 'userId')") || !strings.Contains(got, "@JsonKey(name: 'user_id')") {
+			t.Errorf("%s: the fields should keep their keys:\n%s", style, got)
+		}
+	}
+}
+
+func TestRenderExplicitToJSON(t *testing.T) {
+	classes, err := dartClassesFromJSON("User", []byte(`{"address": {"city": "a"}, "tags": ["a"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, style := range []string{JSONSerializableModel, FreezedModel} {
+		got := renderDartModel(classes, style, "user.dart", nil)
+		if n := strings.Count(got, "@JsonSerializable(explicitToJson: true)"); n != 1 {
+			t.Errorf("%s: want explicitToJson on User only, found %d:\n%s", style, n, got)
+		}
+	}
+	got := renderDartModel(classes, FreezedModel, "user.dart", nil)
+	if !strings.Contains(got, "  @JsonSerializable(explicitToJson: true)\n  const factory User({") {
+		t.Errorf("freezed: explicitToJson should annotate the factory:\n%s", got)
+	}
+}
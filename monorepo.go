@@ -0,0 +1,423 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	monorepoAppsDir     string = "apps"
+	monorepoPackagesDir string = "packages"
+
+	corePackage  string = "core"
+	uiKitPackage string = "ui_kit"
+)
+
+// sampleFeature is the feature the architecture samples implement.
+const sampleFeature string = "counter"
+
+// appEntries are the lib/ entries that make up the composition root and stay
+// in the app package. app/ holds the modules and routes of the state managers
+// wiring the app themselves.
+var appEntries = map[string]bool{
+	"main.dart":                true,
+	"app.dart":                 true,
+	"app":                      true,
+	"injection_container.dart": true,
+	"routes.dart":              true,
+}
+
+var (
+	dartPackageImportPattern = regexp.MustCompile(`package:([a-z0-9_]+)/`)
+	dartPartOfPattern        = regexp.MustCompile(`(?m)^part of\b`)
+)
+
+// workspacePackage is a package created under packages/ and the lib/ paths of
+// the app moved into it.
+type workspacePackage struct {
+	Name  string
+	Path  string
+	Files []string
+}
+
+// checkMonorepo reports the state managers whose sample cannot be split into
+// packages without the feature package depending on the app.
+func checkMonorepo(stateManager string) error {
+	if stateManager == Stacked {
+		return errors.New("Stacked cannot be split into a monorepo, its view models get their services from the locator stacked_generator writes in the app")
+	}
+	return nil
+}
+
+// monorepoPaths returns the workspace root and the path the app is created in.
+func monorepoPaths(opts projectOptions) (workspacePath, appsPath string) {
+	workspacePath = filepath.Join(opts.Path, opts.ProjectName)
+	return workspacePath, filepath.Join(workspacePath, monorepoAppsDir)
+}
+
+func addMelosWorkspace(workspacePath string, opts projectOptions) {
+	os.MkdirAll(filepath.Join(workspacePath, monorepoAppsDir), 0755)
+	os.MkdirAll(filepath.Join(workspacePath, monorepoPackagesDir), 0755)
+
+	pubspecContent := `name: {{name}}_workspace
+publish_to: none
+
+environment:
+  sdk: ">=3.0.0 <4.0.0"
+
+dev_dependencies:
+  melos: ^6.1.0
+`
+	createFile(filepath.Join(workspacePath, "pubspec.yaml"), strings.ReplaceAll(pubspecContent, "{{name}}", opts.ProjectName))
+
+	melosContent := `name: {{name}}
+
+packages:
+  - apps/**
+  - packages/**
+
+scripts:
+  format:
+    exec: dart format --output=none --set-exit-if-changed .
+  analyze:
+    exec: flutter analyze
+  test:
+    exec: flutter test --coverage
+    packageFilters:
+      dirExists: test
+  build_runner:
+    exec: dart run build_runner build --delete-conflicting-outputs
+    packageFilters:
+      dependsOn: build_runner
+`
+	createFile(filepath.Join(workspacePath, "melos.yaml"), strings.ReplaceAll(melosContent, "{{name}}", opts.ProjectName))
+
+	addGitIgnoreEntries(workspacePath, "Melos workspace", []string{".dart_tool/", "pubspec.lock", "pubspec_overrides.yaml"})
+}
+
+// splitMonorepoPackages moves the layers the architecture generated in the
+// app into packages/: core/ goes to core, widgets/ to ui_kit, features/<x>/
+// and the state management folders to feature_<x>. The composition root stays
+// in the app, which depends on the packages through path dependencies.
+func splitMonorepoPackages(workspacePath, projectPath string) []workspacePackage {
+	appPackage := filepath.Base(projectPath)
+	appLib := filepath.Join(projectPath, "lib")
+	packagesPath := filepath.Join(workspacePath, monorepoPackagesDir)
+
+	packages := map[string]*workspacePackage{
+		corePackage:  {Name: corePackage},
+		uiKitPackage: {Name: uiKitPackage},
+	}
+	// Import prefixes of the app rewritten to the package owning them
+	rewrites := make(map[string]string)
+
+	entries, err := os.ReadDir(appLib)
+	if err != nil {
+		fmt.Println("Error reading lib directory:", err)
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if appEntries[name] || !entry.IsDir() {
+			continue
+		}
+
+		switch name {
+		case "core":
+			moveToPackage(appLib, name, "", packages[corePackage], packagesPath)
+			rewrites[appPackage+"/core/"] = corePackage + "/"
+		case "widgets":
+			moveToPackage(appLib, name, "", packages[uiKitPackage], packagesPath)
+			rewrites[appPackage+"/widgets/"] = uiKitPackage + "/"
+		case "features":
+			features, _ := os.ReadDir(filepath.Join(appLib, name))
+			for _, feature := range features {
+				if !feature.IsDir() {
+					continue
+				}
+				pkg := featurePackage(packages, feature.Name())
+				moveToPackage(appLib, filepath.Join(name, feature.Name()), "", pkg, packagesPath)
+				rewrites[appPackage+"/features/"+feature.Name()+"/"] = pkg.Name + "/"
+			}
+		default:
+			pkg := featurePackage(packages, sampleFeature)
+			moveToPackage(appLib, name, name, pkg, packagesPath)
+			rewrites[appPackage+"/"+name+"/"] = pkg.Name + "/" + name + "/"
+		}
+	}
+
+	var names []string
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []workspacePackage
+	for _, name := range names {
+		pkg := packages[name]
+		pkg.Path = filepath.Join(packagesPath, name)
+		createPackage(packagesPath, pkg)
+		result = append(result, *pkg)
+	}
+
+	// Point the imports at the packages now owning the files, the tests of
+	// the app included
+	for _, dir := range append([]string{projectPath}, packagePaths(result)...) {
+		rewriteDartImports(filepath.Join(dir, "lib"), rewrites)
+		rewriteDartImports(filepath.Join(dir, "test"), rewrites)
+	}
+
+	codegenDeps := codegenDevDependencies(projectPath)
+	for _, pkg := range result {
+		addPackageDependencies(pkg.Path, result, codegenDeps)
+	}
+	addPackageDependencies(projectPath, result, nil)
+
+	// Link the packages together
+	cmd1 := exec.Command("dart", "pub", "get")
+	cmd1.Dir = workspacePath
+	executeCommand(cmd1)
+
+	cmd2 := exec.Command("dart", "run", "melos", "bootstrap")
+	cmd2.Dir = workspacePath
+	executeCommand(cmd2)
+
+	return result
+}
+
+func featurePackage(packages map[string]*workspacePackage, feature string) *workspacePackage {
+	name := "feature_" + feature
+	if packages[name] == nil {
+		packages[name] = &workspacePackage{Name: name}
+	}
+	return packages[name]
+}
+
+func packagePaths(packages []workspacePackage) []string {
+	var paths []string
+	for _, pkg := range packages {
+		paths = append(paths, pkg.Path)
+	}
+	return paths
+}
+
+// moveToPackage moves the files under lib/<entry> of the app to lib/<dest>
+// of the package. They are staged next to the package until flutter create
+// has run for it.
+func moveToPackage(appLib, entry, dest string, pkg *workspacePackage, packagesPath string) {
+	source := filepath.Join(appLib, entry)
+	staging := filepath.Join(packagesPath, "."+pkg.Name)
+
+	filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(source, path)
+		target := filepath.Join(staging, dest, rel)
+		os.MkdirAll(filepath.Dir(target), 0755)
+		if err := os.Rename(path, target); err != nil {
+			fmt.Println("Error moving", path, "to", pkg.Name+":", err)
+			return nil
+		}
+		pkg.Files = append(pkg.Files, filepath.ToSlash(filepath.Join(dest, rel)))
+		return nil
+	})
+	os.RemoveAll(source)
+}
+
+// createPackage runs flutter create for the package, moves the staged files
+// into its lib/ and replaces the sample library with one exporting them.
+func createPackage(packagesPath string, pkg *workspacePackage) {
+	cmd := exec.Command("flutter", "create", "--template=package", pkg.Name)
+	cmd.Dir = packagesPath
+	executeCommand(cmd)
+
+	// Drop the Calculator sample flutter create adds
+	os.Remove(filepath.Join(pkg.Path, "test", pkg.Name+"_test.dart"))
+
+	staging := filepath.Join(packagesPath, "."+pkg.Name)
+	if entries, err := os.ReadDir(staging); err == nil {
+		for _, entry := range entries {
+			if err := os.Rename(filepath.Join(staging, entry.Name()), filepath.Join(pkg.Path, "lib", entry.Name())); err != nil {
+				fmt.Println("Error moving", entry.Name(), "to", pkg.Name+":", err)
+			}
+		}
+		os.RemoveAll(staging)
+	}
+
+	var exports []string
+	for _, file := range pkg.Files {
+		if !strings.HasSuffix(file, ".dart") || isDartPart(filepath.Join(pkg.Path, "lib", file)) {
+			continue
+		}
+		exports = append(exports, fmt.Sprintf("export '%s';", file))
+	}
+	sort.Strings(exports)
+
+	libraryContent := "library;\n"
+	if len(exports) > 0 {
+		libraryContent = strings.Join(exports, "\n") + "\n"
+	} else if pkg.Name == uiKitPackage {
+		libraryContent = `
+import 'package:flutter/material.dart';
+
+class AppTheme {
+  const AppTheme._();
+
+  static ThemeData get light {
+    return ThemeData(
+      colorScheme: ColorScheme.fromSeed(seedColor: Colors.deepPurple),
+    );
+  }
+}
+`
+	}
+	createFile(filepath.Join(pkg.Path, "lib", pkg.Name+".dart"), renderDart(pkg.Path, libraryContent))
+}
+
+func isDartPart(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return dartPartOfPattern.Match(content)
+}
+
+// rewriteDartImports replaces the package: import prefixes of every Dart file
+// under dir.
+func rewriteDartImports(dir string, rewrites map[string]string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".dart") {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		updated := string(content)
+		for from, to := range rewrites {
+			updated = strings.ReplaceAll(updated, "package:"+from, "package:"+to)
+		}
+		if updated != string(content) {
+			createFile(path, sortDartImports(updated))
+		}
+		return nil
+	})
+}
+
+// addPackageDependencies adds to the package at dir the workspace packages it
+// imports as path dependencies and the other imported packages from pub.
+func addPackageDependencies(dir string, workspace []workspacePackage, codegenDeps []string) {
+	self := filepath.Base(dir)
+	local := make(map[string]string)
+	for _, pkg := range workspace {
+		local[pkg.Name] = pkg.Path
+	}
+
+	imported := make(map[string]bool)
+	hasParts := false
+	filepath.WalkDir(filepath.Join(dir, "lib"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".dart") {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range dartPackageImportPattern.FindAllStringSubmatch(string(content), -1) {
+			imported[match[1]] = true
+		}
+		if strings.Contains(string(content), ".g.dart';") {
+			hasParts = true
+		}
+		return nil
+	})
+	// Tests may import a workspace package lib/ does not
+	filepath.WalkDir(filepath.Join(dir, "test"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".dart") {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range dartPackageImportPattern.FindAllStringSubmatch(string(content), -1) {
+			if _, ok := local[match[1]]; ok {
+				imported[match[1]] = true
+			}
+		}
+		return nil
+	})
+
+	var args []string
+	for name := range imported {
+		if name == self || name == "flutter" {
+			continue
+		}
+		if path, ok := local[name]; ok {
+			rel, _ := filepath.Rel(dir, path)
+			args = append(args, fmt.Sprintf(`%s:{"path":"%s"}`, name, filepath.ToSlash(rel)))
+		} else {
+			args = append(args, name)
+		}
+	}
+	if hasParts {
+		for _, dep := range codegenDeps {
+			args = append(args, "dev:"+dep)
+		}
+	}
+	if len(args) == 0 {
+		return
+	}
+	sort.Strings(args)
+
+	cmd := exec.Command("flutter", append([]string{"pub", "add"}, args...)...)
+	cmd.Dir = dir
+	executeCommand(cmd)
+}
+
+// codegenDevDependencies returns the code generation dev dependencies of the
+// project at projectPath.
+func codegenDevDependencies(projectPath string) []string {
+	var deps []string
+	for _, dep := range pubspecDependencies(projectPath, "dev_dependencies") {
+		if dep == "build_runner" || dep == "freezed" || dep == "json_serializable" ||
+			strings.HasSuffix(dep, "_codegen") || strings.HasSuffix(dep, "_generator") {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// pubspecDependencies returns the package names listed in a section of the
+// project's pubspec.yaml.
+func pubspecDependencies(projectPath, section string) []string {
+	content, err := os.ReadFile(filepath.Join(projectPath, "pubspec.yaml"))
+	if err != nil {
+		fmt.Println("Error reading pubspec.yaml:", err)
+		return nil
+	}
+
+	var deps []string
+	inSection := false
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "" {
+			inSection = strings.TrimSpace(line) == section+":"
+			continue
+		}
+		if !inSection || strings.HasPrefix(line, "    ") {
+			continue
+		}
+		if name, _, ok := strings.Cut(strings.TrimSpace(line), ":"); ok && !strings.HasPrefix(name, "#") {
+			deps = append(deps, name)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitMonorepoPackagesRewritesTestImports(t *testing.T) {
+	useFakeFlutter(t)
+
+	workspace := t.TempDir()
+	project := filepath.Join(workspace, monorepoAppsDir, "demo")
+	writeTestFiles(t, project, map[string]string{
+		"pubspec.yaml":                            "name: demo\n\ndependencies:\n  flutter:\n    sdk: flutter\n",
+		"lib/main.dart":                           "import 'package:demo/app.dart';\n\nvoid main() => runApp(const App());\n",
+		"lib/app.dart":                            "import 'package:demo/features/counter/counter_page.dart';\n",
+		"lib/app/app_module.dart":                 "import 'package:demo/features/counter/counter_page.dart';\n",
+		"lib/core/api_client.dart":                "class ApiClient {}\n",
+		"lib/features/counter/counter_page.dart":  "import 'package:demo/features/counter/counter_store.dart';\n",
+		"lib/features/counter/counter_store.dart": "import 'package:demo/core/api_client.dart';\n",
+		"test/features/counter/counter_store_test.dart": "import 'package:demo/core/api_client.dart';\n" +
+			"import 'package:demo/features/counter/counter_store.dart';\n" +
+			"import 'package:flutter_test/flutter_test.dart';\n",
+		"test/widget_test.dart": "import 'package:demo/main.dart';\n",
+	})
+	os.MkdirAll(filepath.Join(workspace, monorepoPackagesDir), 0755)
+
+	packages := splitMonorepoPackages(workspace, project)
+
+	var names []string
+	for _, pkg := range packages {
+		names = append(names, pkg.Name)
+	}
+	if got := strings.Join(names, ","); got != "core,feature_counter,ui_kit" {
+		t.Fatalf("packages = %s, want core,feature_counter,ui_kit", got)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, monorepoPackagesDir, "feature_counter", "lib", "counter_store.dart")); err != nil {
+		t.Errorf("counter_store.dart was not moved to feature_counter: %v", err)
+	}
+
+	storeTest := readTestFile(t, filepath.Join(project, "test", "features", "counter", "counter_store_test.dart"))
+	for _, want := range []string{
+		"import 'package:core/api_client.dart';",
+		"import 'package:feature_counter/counter_store.dart';",
+		"import 'package:flutter_test/flutter_test.dart';",
+	} {
+		if !strings.Contains(storeTest, want) {
+			t.Errorf("counter_store_test.dart is missing %q:\n%s", want, storeTest)
+		}
+	}
+	if strings.Contains(storeTest, "package:demo/") {
+		t.Errorf("counter_store_test.dart still imports the moved files from the app:\n%s", storeTest)
+	}
+
+	// The composition root stays in the app
+	appModule := readTestFile(t, filepath.Join(project, "lib", "app", "app_module.dart"))
+	if !strings.Contains(appModule, "package:feature_counter/counter_page.dart") {
+		t.Errorf("app_module.dart should stay in the app and import the feature package:\n%s", appModule)
+	}
+	if widgetTest := readTestFile(t, filepath.Join(project, "test", "widget_test.dart")); !strings.Contains(widgetTest, "package:demo/main.dart") {
+		t.Errorf("widget_test.dart should keep importing the app:\n%s", widgetTest)
+	}
+}
+
+func TestValidatePresetRejectsStackedMonorepo(t *testing.T) {
+	p := preset{Pattern: patterns[0], StateManager: Stacked, Monorepo: true}
+	if err := validatePreset(p); err == nil {
+		t.Error("Stacked with a monorepo should be rejected")
+	}
+	p.Monorepo = false
+	if err := validatePreset(p); err != nil {
+		t.Errorf("Stacked without a monorepo should be accepted: %v", err)
+	}
+}
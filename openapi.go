@@ -0,0 +1,919 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// orderedMap is a YAML mapping keeping the order of its keys, so that the
+// generated code follows the document.
+type orderedMap[T any] struct {
+	Keys   []string
+	Values map[string]T
+}
+
+func (m *orderedMap[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	m.Values = make(map[string]T)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		if _, ok := m.Values[key]; !ok {
+			m.Keys = append(m.Keys, key)
+		}
+		m.Values[key] = value
+	}
+	return nil
+}
+
+// openAPIDocument is the part of an OpenAPI 3 document the generator uses.
+type openAPIDocument struct {
+	OpenAPI string `yaml:"openapi"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths      orderedMap[openAPIPathItem] `yaml:"paths"`
+	Components struct {
+		Schemas       orderedMap[*openAPISchema]    `yaml:"schemas"`
+		Parameters    map[string]openAPIParameter   `yaml:"parameters"`
+		RequestBodies map[string]openAPIRequestBody `yaml:"requestBodies"`
+		Responses     map[string]openAPIResponse    `yaml:"responses"`
+	} `yaml:"components"`
+}
+
+type openAPIPathItem struct {
+	Parameters []openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation  `yaml:"get"`
+	Put        *openAPIOperation  `yaml:"put"`
+	Post       *openAPIOperation  `yaml:"post"`
+	Delete     *openAPIOperation  `yaml:"delete"`
+	Patch      *openAPIOperation  `yaml:"patch"`
+	Head       *openAPIOperation  `yaml:"head"`
+	Options    *openAPIOperation  `yaml:"options"`
+}
+
+type openAPIOperation struct {
+	OperationID string                      `yaml:"operationId"`
+	Summary     string                      `yaml:"summary"`
+	Tags        []string                    `yaml:"tags"`
+	Parameters  []openAPIParameter          `yaml:"parameters"`
+	RequestBody *openAPIRequestBody         `yaml:"requestBody"`
+	Responses   orderedMap[openAPIResponse] `yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `yaml:"$ref"`
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required"`
+	Schema   *openAPISchema `yaml:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref      string                      `yaml:"$ref"`
+	Required bool                        `yaml:"required"`
+	Content  map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `yaml:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                     `yaml:"$ref"`
+	Type       string                     `yaml:"type"`
+	Format     string                     `yaml:"format"`
+	Nullable   bool                       `yaml:"nullable"`
+	Required   []string                   `yaml:"required"`
+	Properties orderedMap[*openAPISchema] `yaml:"properties"`
+	Items      *openAPISchema             `yaml:"items"`
+	AllOf      []*openAPISchema           `yaml:"allOf"`
+}
+
+// apiLayout tells where the generated API code goes in a project.
+type apiLayout struct {
+	Clean bool
+	// Folders relative to lib/
+	ClientDir string
+	ModelsDir string
+}
+
+// apiOperation is an endpoint of the API, generated as a method of the API
+// of its tag and of the matching repository.
+type apiOperation struct {
+	Name   string
+	Method string
+	Path   string
+	Params []dartField
+	// In tells where each parameter goes: path, query, header or body
+	In      map[string]string
+	Returns *dartField
+}
+
+// apiGenerator renders the Dart code of an OpenAPI document.
+type apiGenerator struct {
+	doc     *openAPIDocument
+	pkg     string
+	layout  apiLayout
+	style   string
+	classes map[string][]dartClass
+	// classFiles maps each generated class to the file declaring it
+	classFiles map[string]string
+	files      []string
+}
+
+var openAPIPathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+func apiCommand(args []string) {
+	fs := flag.NewFlagSet("api", flag.ExitOnError)
+	style := fs.String("style", "", "model style: plain, json_serializable or freezed (detected from pubspec.yaml by default)")
+	project := fs.String("project", ".", "path of the Flutter project")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: flutter-arch api [flags] <openapi.yaml|openapi.json>")
+		fs.PrintDefaults()
+	}
+	positional := parseInterspersed(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		return
+	}
+
+	content, err := os.ReadFile(positional[0])
+	if err != nil {
+		fmt.Println("Error reading OpenAPI document:", err)
+		return
+	}
+	// JSON documents are valid YAML
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		fmt.Println("Error parsing OpenAPI document:", err)
+		return
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		fmt.Printf("OpenAPI version %q is not supported, only OpenAPI 3 documents are.\n", doc.OpenAPI)
+		return
+	}
+
+	projectPath := *project
+	if *style == "" {
+		*style = detectModelStyle(projectPath)
+	}
+	switch *style {
+	case PlainModel, JSONSerializableModel, FreezedModel:
+	default:
+		fmt.Printf("Model style %s is not supported.\n", *style)
+		return
+	}
+
+	// Add necessary packages for the API client and the models
+	cmd1 := exec.Command("flutter", "pub", "add", "http", "get_it")
+	cmd1.Dir = projectPath
+	executeCommand(cmd1)
+	addModelPackages(projectPath, *style)
+
+	g := &apiGenerator{
+		doc:        &doc,
+		pkg:        pubspecName(projectPath),
+		layout:     detectAPILayout(projectPath),
+		style:      *style,
+		classes:    make(map[string][]dartClass),
+		classFiles: make(map[string]string),
+	}
+	files := g.render()
+	for _, file := range files {
+		filePath := filepath.Join(projectPath, filepath.FromSlash(file.Path))
+		os.MkdirAll(filepath.Dir(filePath), 0755)
+		createFile(filePath, file.Content)
+		fmt.Println("Created", file.Path)
+	}
+	registerAPI(projectPath, g)
+
+	if *style != PlainModel {
+		cmd2 := exec.Command("dart", "run", "build_runner", "build", "--delete-conflicting-outputs")
+		cmd2.Dir = projectPath
+		executeCommand(cmd2)
+	}
+
+	cmd3 := exec.Command("dart", "format", "lib")
+	cmd3.Dir = projectPath
+	executeCommand(cmd3)
+}
+
+// pubspecName returns the package name of a project.
+func pubspecName(projectPath string) string {
+	content, err := os.ReadFile(filepath.Join(projectPath, "pubspec.yaml"))
+	if err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.HasPrefix(line, "name:") {
+				return strings.TrimSpace(strings.TrimPrefix(line, "name:"))
+			}
+		}
+	}
+	abs, _ := filepath.Abs(projectPath)
+	return filepath.Base(abs)
+}
+
+// detectAPILayout places the API code like the architecture of the project:
+// in the network layer of core/ and one feature per tag for Clean
+// Architecture, in api/ next to the models otherwise.
+func detectAPILayout(projectPath string) apiLayout {
+	lib := filepath.Join(projectPath, "lib")
+	if info, err := os.Stat(filepath.Join(lib, "features")); err == nil && info.IsDir() {
+		return apiLayout{Clean: true, ClientDir: "core/network", ModelsDir: "core/network/models"}
+	}
+	if info, err := os.Stat(filepath.Join(lib, "model")); err == nil && info.IsDir() {
+		return apiLayout{ClientDir: "api", ModelsDir: "model"}
+	}
+	return apiLayout{ClientDir: "api", ModelsDir: "models"}
+}
+
+func (g *apiGenerator) uri(file string) string {
+	return "package:" + g.pkg + "/" + file
+}
+
+// render returns the client, models, APIs and repositories of the document,
+// with paths relative to the project.
+func (g *apiGenerator) render() []generatedFile {
+	var files []generatedFile
+	add := func(file, content string) {
+		files = append(files, generatedFile{Path: "lib/" + file, Content: sortDartImports(tidyBlankLines(content))})
+	}
+
+	add(path.Join(g.layout.ClientDir, "api_client.dart"), strings.ReplaceAll(apiClientTemplate, "{{baseUrl}}", g.baseURL()))
+
+	// Models of the component schemas, one file each
+	for _, name := range g.doc.Components.Schemas.Keys {
+		if schema := g.doc.Components.Schemas.Values[name]; g.isObject(schema) {
+			g.classFiles[g.className(name)] = g.modelFile(g.className(name))
+		}
+	}
+	for _, name := range g.doc.Components.Schemas.Keys {
+		schema := g.doc.Components.Schemas.Values[name]
+		if g.isObject(schema) {
+			g.addClass(g.className(name), schema, g.modelFile(g.className(name)))
+		}
+	}
+
+	tags, operations := g.operations()
+
+	for _, file := range g.files {
+		imports := g.modelImports(file, g.classes[file])
+		add(file, renderDartModel(g.classes[file], g.style, path.Base(file), imports))
+	}
+	for _, tag := range tags {
+		add(g.apiFile(tag), g.renderAPI(tag, operations[tag]))
+		add(g.repositoryFile(tag), g.renderRepository(tag, operations[tag]))
+		if g.layout.Clean {
+			add(g.repositoryImplFile(tag), g.renderRepositoryImpl(tag, operations[tag]))
+		}
+	}
+	add(g.injectionFile(), g.renderInjection(tags))
+	return files
+}
+
+func (g *apiGenerator) baseURL() string {
+	if len(g.doc.Servers) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(g.doc.Servers[0].URL, "/")
+}
+
+func (g *apiGenerator) className(name string) string {
+	if isDartClassName(name) {
+		return name
+	}
+	return dartClassName(name)
+}
+
+func (g *apiGenerator) modelFile(class string) string {
+	return path.Join(g.layout.ModelsDir, snakeCase(class)+".dart")
+}
+
+// resolveSchema follows the reference of a schema to the components.
+func (g *apiGenerator) resolveSchema(schema *openAPISchema) (*openAPISchema, string) {
+	for schema != nil && schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		next, ok := g.doc.Components.Schemas.Values[name]
+		if !ok {
+			return nil, ""
+		}
+		if g.isObject(next) {
+			return next, name
+		}
+		schema = next
+	}
+	return schema, ""
+}
+
+// isObject reports whether a schema is generated as a class.
+func (g *apiGenerator) isObject(schema *openAPISchema) bool {
+	if schema == nil || schema.Ref != "" {
+		return false
+	}
+	return len(schema.Properties.Keys) > 0 || len(schema.AllOf) > 0
+}
+
+// addClass generates the class of an object schema and of its inline
+// objects in file.
+func (g *apiGenerator) addClass(name string, schema *openAPISchema, file string) {
+	if _, ok := g.classes[file]; !ok {
+		g.files = append(g.files, file)
+	}
+	g.classFiles[name] = file
+	index := len(g.classes[file])
+	g.classes[file] = append(g.classes[file], dartClass{Name: name})
+
+	var fields []dartField
+	for _, part := range append([]*openAPISchema{schema}, schema.AllOf...) {
+		// Inline objects of a referenced schema keep the class generated
+		// for it
+		owner := name
+		if part != schema {
+			resolved, ref := g.resolveSchema(part)
+			if resolved == nil {
+				continue
+			}
+			if ref != "" {
+				owner = g.className(ref)
+			}
+			part = resolved
+		}
+		required := make(map[string]bool)
+		for _, key := range part.Required {
+			required[key] = true
+		}
+		for _, key := range part.Properties.Keys {
+			field := g.field(key, part.Properties.Values[key], owner+dartClassName(key), file)
+			field.Nullable = field.Nullable || !required[key]
+			fields = append(fields, field)
+		}
+	}
+	g.classes[file][index].Fields = fields
+}
+
+// field returns the Dart field of a schema, generating the class of an inline
+// object as hint in file.
+func (g *apiGenerator) field(key string, schema *openAPISchema, hint, file string) dartField {
+	field := dartField{Key: key, Name: dartFieldName(key)}
+	if schema != nil && schema.Nullable {
+		field.Nullable = true
+	}
+
+	resolved, ref := g.resolveSchema(schema)
+	if resolved != nil && resolved.Type == "array" {
+		field.List = 1
+		resolved, ref = g.resolveSchema(resolved.Items)
+		hint = singular(hint)
+	}
+
+	switch {
+	case ref != "":
+		field.Class = g.className(ref)
+		field.Type = field.Class
+	case resolved == nil:
+		field.Type = "Object"
+		field.Nullable = true
+	case g.isObject(resolved):
+		field.Class = hint
+		field.Type = hint
+		if _, ok := g.classFiles[hint]; !ok {
+			g.addClass(hint, resolved, file)
+		}
+	case resolved.Type == "array" && field.List > 0:
+		field.Type = "dynamic"
+	default:
+		field.Type = dartScalarType(resolved)
+		if field.Type == "Object" {
+			field.Nullable = true
+		}
+	}
+	return field
+}
+
+// dartScalarType returns the Dart type of a schema that is not a class.
+func dartScalarType(schema *openAPISchema) string {
+	switch schema.Type {
+	case "string":
+		if schema.Format == "date-time" || schema.Format == "date" {
+			return "DateTime"
+		}
+		return "String"
+	case "integer":
+		return "int"
+	case "number":
+		return "double"
+	case "boolean":
+		return "bool"
+	case "object":
+		return "Map<String, dynamic>"
+	}
+	return "Object"
+}
+
+// modelImports returns the URIs of the models the classes of file reference.
+func (g *apiGenerator) modelImports(file string, classes []dartClass) []string {
+	seen := make(map[string]bool)
+	var imports []string
+	for _, class := range classes {
+		for _, field := range class.Fields {
+			other, ok := g.classFiles[field.Class]
+			if ok && other != file && !seen[other] {
+				seen[other] = true
+				imports = append(imports, g.uri(other))
+			}
+		}
+	}
+	return imports
+}
+
+// operations groups the operations of the document by tag, in order.
+func (g *apiGenerator) operations() ([]string, map[string][]apiOperation) {
+	var tags []string
+	operations := make(map[string][]apiOperation)
+	for _, p := range g.doc.Paths.Keys {
+		item := g.doc.Paths.Values[p]
+		methods := []struct {
+			name string
+			op   *openAPIOperation
+		}{
+			{"GET", item.Get}, {"PUT", item.Put}, {"POST", item.Post}, {"DELETE", item.Delete},
+			{"PATCH", item.Patch}, {"HEAD", item.Head}, {"OPTIONS", item.Options},
+		}
+		for _, m := range methods {
+			if m.op == nil {
+				continue
+			}
+			tag := "default"
+			if len(m.op.Tags) > 0 {
+				tag = m.op.Tags[0]
+			}
+			if _, ok := operations[tag]; !ok {
+				tags = append(tags, tag)
+			}
+			operations[tag] = append(operations[tag], g.operation(m.name, p, item.Parameters, m.op))
+		}
+	}
+	return tags, operations
+}
+
+func (g *apiGenerator) operation(method, p string, shared []openAPIParameter, op *openAPIOperation) apiOperation {
+	name := op.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + " " + p
+	}
+	o := apiOperation{Name: dartFieldName(name), Method: method, Path: p, In: make(map[string]string)}
+	className := g.className(dartClassName(name))
+
+	// Operation parameters override the ones shared by the path
+	params := make(map[string]openAPIParameter)
+	var order []string
+	for _, param := range append(append([]openAPIParameter{}, shared...), op.Parameters...) {
+		if param.Ref != "" {
+			param = g.doc.Components.Parameters[strings.TrimPrefix(param.Ref, "#/components/parameters/")]
+		}
+		if param.In == "cookie" || param.Name == "" {
+			continue
+		}
+		id := param.In + ":" + param.Name
+		if _, ok := params[id]; !ok {
+			order = append(order, id)
+		}
+		params[id] = param
+	}
+	// Parameters in different places may share a name, such as a path id
+	// and a header id, the Dart parameters are numbered like fields
+	used := make(map[string]bool)
+	for _, id := range order {
+		param := params[id]
+		field := g.field(param.Name, param.Schema, className+dartClassName(param.Name), g.modelFile(className))
+		field.Name = uniqueFieldName(field.Name, used)
+		field.Nullable = field.Nullable || !(param.Required || param.In == "path")
+		o.Params = append(o.Params, field)
+		o.In[field.Name] = param.In
+	}
+
+	if body := op.RequestBody; body != nil {
+		if body.Ref != "" {
+			resolved := g.doc.Components.RequestBodies[strings.TrimPrefix(body.Ref, "#/components/requestBodies/")]
+			body = &resolved
+		}
+		if schema := jsonSchema(body.Content); schema != nil {
+			field := g.field("body", schema, className+"Request", g.modelFile(className+"Request"))
+			field.Name = uniqueFieldName(field.Name, used)
+			field.Nullable = field.Nullable || !body.Required
+			o.Params = append(o.Params, field)
+			o.In[field.Name] = "body"
+		}
+	}
+
+	for _, code := range op.Responses.Keys {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		response := op.Responses.Values[code]
+		if response.Ref != "" {
+			response = g.doc.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+		}
+		if schema := jsonSchema(response.Content); schema != nil {
+			field := g.field("response", schema, className+"Response", g.modelFile(className+"Response"))
+			o.Returns = &field
+		}
+		break
+	}
+	return o
+}
+
+// jsonSchema returns the schema of the JSON content of a body, if any.
+func jsonSchema(content map[string]openAPIMediaType) *openAPISchema {
+	for mediaType, media := range content {
+		if strings.Contains(mediaType, "json") {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+// Names of the generated classes and files of a tag
+func (g *apiGenerator) apiClass(tag string) string {
+	if g.layout.Clean {
+		return dartClassName(tag) + "RemoteDataSource"
+	}
+	return dartClassName(tag) + "Api"
+}
+
+func (g *apiGenerator) apiFile(tag string) string {
+	if g.layout.Clean {
+		return path.Join("features", snakeCase(dartClassName(tag)), "data", "datasources", snakeCase(g.apiClass(tag))+".dart")
+	}
+	return path.Join(g.layout.ClientDir, snakeCase(g.apiClass(tag))+".dart")
+}
+
+func (g *apiGenerator) repositoryFile(tag string) string {
+	name := snakeCase(dartClassName(tag)) + "_repository.dart"
+	if g.layout.Clean {
+		return path.Join("features", snakeCase(dartClassName(tag)), "domain", "repositories", name)
+	}
+	return path.Join(path.Dir(g.layout.ModelsDir), "repository", name)
+}
+
+func (g *apiGenerator) repositoryImplFile(tag string) string {
+	return path.Join("features", snakeCase(dartClassName(tag)), "data", "repositories", snakeCase(dartClassName(tag))+"_repository_impl.dart")
+}
+
+func (g *apiGenerator) injectionFile() string {
+	return path.Join(g.layout.ClientDir, "api_injection.dart")
+}
+
+// operationImports returns the URIs of the models used by operations.
+func (g *apiGenerator) operationImports(operations []apiOperation) string {
+	seen := make(map[string]bool)
+	var b strings.Builder
+	for _, o := range operations {
+		fields := o.Params
+		if o.Returns != nil {
+			fields = append(append([]dartField{}, fields...), *o.Returns)
+		}
+		for _, field := range fields {
+			if file, ok := g.classFiles[field.Class]; ok && !seen[file] {
+				seen[file] = true
+				fmt.Fprintf(&b, "import '%s';\n", g.uri(file))
+			}
+		}
+	}
+	return b.String()
+}
+
+// signature returns the declaration of the method of an operation.
+func (o apiOperation) signature() string {
+	returns := "void"
+	if o.Returns != nil {
+		returns = o.Returns.dartType()
+	}
+	params := constructorParams(dartClass{Fields: o.Params}, func(f dartField) string {
+		if f.Nullable {
+			return f.dartType() + " " + f.Name
+		}
+		return "required " + f.dartType() + " " + f.Name
+	})
+	if params != "" {
+		params = "{\n" + params + "  }"
+	}
+	return fmt.Sprintf("Future<%s> %s(%s)", returns, o.Name, params)
+}
+
+// arguments returns the arguments forwarding the parameters of an operation.
+func (o apiOperation) arguments() string {
+	var args []string
+	for _, param := range o.Params {
+		args = append(args, param.Name+": "+param.Name)
+	}
+	return strings.Join(args, ", ")
+}
+
+func (g *apiGenerator) renderAPI(tag string, operations []apiOperation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "import '%s';\n%s\n", g.uri(path.Join(g.layout.ClientDir, "api_client.dart")), g.operationImports(operations))
+	fmt.Fprintf(&b, "class %s {\n  const %s(this._client);\n\n  final ApiClient _client;\n", g.apiClass(tag), g.apiClass(tag))
+
+	for _, o := range operations {
+		fmt.Fprintf(&b, "\n  %s async {\n", o.signature())
+
+		urlPath := openAPIPathParam.ReplaceAllStringFunc(o.Path, func(match string) string {
+			key := match[1 : len(match)-1]
+			name := dartFieldName(key)
+			for _, param := range o.Params {
+				if o.In[param.Name] == "path" && param.Key == key {
+					if param.Type == "String" {
+						return "${Uri.encodeComponent(" + param.Name + ")}"
+					}
+					name = param.Name
+					break
+				}
+			}
+			return "${Uri.encodeComponent(" + name + ".toString())}"
+		})
+
+		var args []string
+		args = append(args, dartString(o.Method), "'"+strings.ReplaceAll(urlPath, "'", `\'`)+"'")
+		var query, headers []string
+		for _, param := range o.Params {
+			switch o.In[param.Name] {
+			case "query":
+				query = append(query, fmt.Sprintf("%s: %s", dartString(param.Key), plainToJSON(param)))
+			case "header":
+				value := param.Name
+				if param.Type != "String" || param.List > 0 {
+					value = param.Name + ".toString()"
+				}
+				if param.Nullable {
+					headers = append(headers, fmt.Sprintf("if (%s != null) %s: %s", param.Name, dartString(param.Key), value))
+				} else {
+					headers = append(headers, fmt.Sprintf("%s: %s", dartString(param.Key), value))
+				}
+			case "body":
+				args = append(args, "body: "+plainToJSON(param))
+			}
+		}
+		if len(query) > 0 {
+			args = append(args, "query: {"+strings.Join(query, ", ")+"}")
+		}
+		if len(headers) > 0 {
+			args = append(args, "headers: {"+strings.Join(headers, ", ")+"}")
+		}
+
+		call := "_client.send(" + strings.Join(args, ", ") + ")"
+		if o.Returns == nil {
+			fmt.Fprintf(&b, "    await %s;\n  }\n", call)
+			continue
+		}
+		// The decoded response must not shadow a parameter, such as a query
+		// parameter named json
+		taken := make(map[string]bool)
+		for _, param := range o.Params {
+			taken[param.Name] = true
+		}
+		local := uniqueFieldName("json", taken)
+		fmt.Fprintf(&b, "    final %s = await %s;\n    return %s;\n  }\n", local, call, plainFromJSON(*o.Returns, local))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *apiGenerator) renderRepository(tag string, operations []apiOperation) string {
+	name := dartClassName(tag) + "Repository"
+	var b strings.Builder
+	b.WriteString(g.operationImports(operations))
+	if !g.layout.Clean {
+		fmt.Fprintf(&b, "import '%s';\n", g.uri(g.apiFile(tag)))
+	}
+
+	fmt.Fprintf(&b, "\nabstract interface class %s {\n", name)
+	for i, o := range operations {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "  %s;\n", o.signature())
+	}
+	b.WriteString("}\n")
+
+	if !g.layout.Clean {
+		b.WriteString("\n")
+		g.writeRepositoryImpl(&b, tag, operations)
+	}
+	return b.String()
+}
+
+func (g *apiGenerator) renderRepositoryImpl(tag string, operations []apiOperation) string {
+	var b strings.Builder
+	b.WriteString(g.operationImports(operations))
+	fmt.Fprintf(&b, "import '%s';\nimport '%s';\n\n", g.uri(g.apiFile(tag)), g.uri(g.repositoryFile(tag)))
+	g.writeRepositoryImpl(&b, tag, operations)
+	return b.String()
+}
+
+// writeRepositoryImpl writes the repository forwarding every operation to the
+// API of the tag.
+func (g *apiGenerator) writeRepositoryImpl(b *strings.Builder, tag string, operations []apiOperation) {
+	name := dartClassName(tag) + "Repository"
+	fmt.Fprintf(b, "class %sImpl implements %s {\n  const %sImpl(this._api);\n\n  final %s _api;\n", name, name, name, g.apiClass(tag))
+	for _, o := range operations {
+		fmt.Fprintf(b, "\n  @override\n  %s {\n    return _api.%s(%s);\n  }\n", o.signature(), o.Name, o.arguments())
+	}
+	b.WriteString("}\n")
+}
+
+func (g *apiGenerator) renderInjection(tags []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "import 'package:get_it/get_it.dart';\nimport '%s';\n", g.uri(path.Join(g.layout.ClientDir, "api_client.dart")))
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "import '%s';\n", g.uri(g.apiFile(tag)))
+		fmt.Fprintf(&b, "import '%s';\n", g.uri(g.repositoryFile(tag)))
+		if g.layout.Clean {
+			fmt.Fprintf(&b, "import '%s';\n", g.uri(g.repositoryImplFile(tag)))
+		}
+	}
+
+	b.WriteString("\n/// Registers the API client, the API of every tag and its repository.\nvoid initApi(GetIt sl) {\n  if (!sl.isRegistered<ApiClient>()) {\n    sl.registerLazySingleton(ApiClient.new);\n  }\n")
+	for _, tag := range tags {
+		name := dartClassName(tag) + "Repository"
+		fmt.Fprintf(&b, "  sl\n    ..registerLazySingleton(() => %s(sl()))\n", g.apiClass(tag))
+		fmt.Fprintf(&b, "    ..registerLazySingleton<%s>(() => %sImpl(sl()));\n", name, name)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// registerAPI calls initApi from the dependency injection container of the
+// project, creating one initialized by main when there is none.
+func registerAPI(projectPath string, g *apiGenerator) {
+	registerDependency(projectPath, "initApi(sl);", g.uri(g.injectionFile()))
+}
+
+// registerDependency adds a registration statement to init() of the
+// injection container, creating the container when the project has none.
+func registerDependency(projectPath, statement string, importURIs ...string) {
+	containerPath := filepath.Join(projectPath, "lib", "injection_container.dart")
+	containerURI := "package:" + pubspecName(projectPath) + "/injection_container.dart"
+	content, err := os.ReadFile(containerPath)
+	if os.IsNotExist(err) {
+		container, _ := withRegistration(`import 'package:get_it/get_it.dart';
+
+final sl = GetIt.instance;
+
+void init() {}
+`, statement, importURIs...)
+		createFile(containerPath, container)
+		addMainInit(projectPath, containerURI)
+		return
+	}
+	if err != nil {
+		fmt.Println("Error reading injection_container.dart:", err)
+		return
+	}
+
+	container, ok := withRegistration(string(content), statement, importURIs...)
+	if !ok {
+		fmt.Printf("Could not find init() in injection_container.dart, add %s to it.\n", statement)
+		return
+	}
+	createFile(containerPath, container)
+}
+
+// withRegistration returns the injection container with a registration
+// statement added to its init(), and false when it has no init().
+func withRegistration(container, statement string, importURIs ...string) (string, bool) {
+	if strings.Contains(container, statement) {
+		return container, true
+	}
+	switch {
+	case strings.Contains(container, "void init() {}"):
+		container = strings.Replace(container, "void init() {}", "void init() {\n  "+statement+"\n}", 1)
+	case strings.Contains(container, "void init() {\n"):
+		container = strings.Replace(container, "void init() {\n", "void init() {\n  "+statement+"\n", 1)
+	default:
+		return container, false
+	}
+	for _, uri := range importURIs {
+		container = "import '" + uri + "';\n" + container
+	}
+	return sortDartImports(container), true
+}
+
+// addMainInit makes main initialize the dependency injection container.
+func addMainInit(projectPath, containerURI string) {
+	mainPath := filepath.Join(projectPath, "lib", "main.dart")
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		fmt.Println("Error reading main.dart:", err)
+		return
+	}
+	main, ok := withMainInit(string(content), containerURI)
+	if !ok {
+		fmt.Println("Could not find main() in main.dart, call di.init() from it to register the dependencies.")
+		return
+	}
+	createFile(mainPath, main)
+}
+
+// withMainInit returns main.dart with its main initializing the dependency
+// injection container, and false when it has no main.
+func withMainInit(main, containerURI string) (string, bool) {
+	if strings.Contains(main, "di.init();") {
+		return main, true
+	}
+	declaration := "void main() {\n"
+	if !strings.Contains(main, declaration) {
+		declaration = "Future<void> main() async {\n"
+		if !strings.Contains(main, declaration) {
+			return main, false
+		}
+	}
+	main = strings.Replace(main, declaration, declaration+"  di.init();\n", 1)
+	main = "import '" + containerURI + "' as di;\n" + main
+	return sortDartImports(main), true
+}
+
+// apiClientTemplate sends the requests of every API and decodes their JSON
+// responses.
+const apiClientTemplate = `
+import 'dart:convert';
+
+import 'package:http/http.dart' as http;
+
+class ApiException implements Exception {
+  const ApiException(this.statusCode, this.body);
+
+  final int statusCode;
+  final String body;
+
+  @override
+  String toString() => 'ApiException($statusCode): $body';
+}
+
+class ApiClient {
+  ApiClient({this.baseUrl = '{{baseUrl}}', http.Client? httpClient})
+      : _httpClient = httpClient ?? http.Client();
+
+  final String baseUrl;
+  final http.Client _httpClient;
+
+  Future<Object?> send(
+    String method,
+    String path, {
+    Map<String, Object?> query = const {},
+    Map<String, String> headers = const {},
+    Object? body,
+  }) async {
+    final queryParameters = {
+      for (final entry in query.entries)
+        if (entry.value != null) entry.key: _queryValue(entry.value),
+    };
+    final uri = Uri.parse('$baseUrl$path').replace(
+      queryParameters: queryParameters.isEmpty ? null : queryParameters,
+    );
+    final request = http.Request(method, uri)
+      ..headers.addAll({'Accept': 'application/json', ...headers});
+    if (body != null) {
+      request
+        ..headers['Content-Type'] = 'application/json'
+        ..body = jsonEncode(body);
+    }
+
+    final response = await http.Response.fromStream(
+      await _httpClient.send(request),
+    );
+    if (response.statusCode < 200 || response.statusCode >= 300) {
+      throw ApiException(response.statusCode, response.body);
+    }
+    if (response.body.isEmpty) {
+      return null;
+    }
+    return jsonDecode(response.body);
+  }
+
+  static Object _queryValue(Object? value) {
+    if (value is Iterable<Object?>) {
+      return value.map((e) => '$e').toList();
+    }
+    return '$value';
+  }
+}
+`
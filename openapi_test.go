@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// renderTestAPI renders the OpenAPI document with plain models and returns
+// the content of the generated file ending with name.
+func renderTestAPI(t *testing.T, document, name string) string {
+	t.Helper()
+	var doc openAPIDocument
+	if err := yaml.Unmarshal([]byte(document), &doc); err != nil {
+		t.Fatal(err)
+	}
+	g := &apiGenerator{
+		doc:        &doc,
+		pkg:        "demo",
+		layout:     apiLayout{ClientDir: "api", ModelsDir: "models"},
+		style:      PlainModel,
+		classes:    make(map[string][]dartClass),
+		classFiles: make(map[string]string),
+	}
+	for _, file := range g.render() {
+		if strings.HasSuffix(file.Path, name) {
+			return file.Content
+		}
+	}
+	t.Fatalf("%s was not generated", name)
+	return ""
+}
+
+func TestRenderAPIResponseLocal(t *testing.T) {
+	document := `
+openapi: 3.0.0
+paths:
+  /search:
+    get:
+      operationId: search
+      tags: [search]
+      parameters:
+        - name: json
+          in: query
+          schema:
+            type: boolean
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: string
+`
+	api := renderTestAPI(t, document, "search_api.dart")
+	for _, want := range []string{
+		"query: {'json': json}",
+		"final json2 = await _client.send(",
+		"return (json2 as List<dynamic>).map((e) => e as String).toList();",
+	} {
+		if !strings.Contains(api, want) {
+			t.Errorf("missing %q in:\n%s", want, api)
+		}
+	}
+}
+
+func TestRenderAPIParameterNames(t *testing.T) {
+	document := `
+openapi: 3.0.0
+paths:
+  /items/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    put:
+      operationId: updateItem
+      tags: [items]
+      parameters:
+        - name: id
+          in: header
+          schema:
+            type: string
+        - name: body
+          in: query
+          required: true
+          schema:
+            type: string
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+      responses:
+        "204":
+          description: updated
+`
+	api := renderTestAPI(t, document, "items_api.dart")
+	for _, want := range []string{
+		"required int id,",
+		"String? id2,",
+		"required String body,",
+		"required UpdateItemRequest body2,",
+		"'/items/${Uri.encodeComponent(id.toString())}'",
+		"query: {'body': body}",
+		"headers: {if (id2 != null) 'id': id2}",
+		"body: body2.toJson()",
+	} {
+		if !strings.Contains(api, want) {
+			t.Errorf("missing %q in:\n%s", want, api)
+		}
+	}
+}
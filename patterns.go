@@ -0,0 +1,224 @@
+package main
+
+// patternFiles holds the files a layout pattern adds next to the state
+// holder and the page, whatever the state manager. Their content is a Dart
+// template rendered like the state manager ones.
+var patternFiles = map[string][]generatedFile{
+	Mvvm: {
+		{"lib/model/counter_repository.dart", `
+class CounterRepository {
+  int _count = 0;
+
+  int increment() => ++_count;
+}
+`},
+	},
+
+	Mvc: {
+		{"lib/model/counter_model.dart", `
+class CounterModel {
+  int _count = 0;
+
+  int get count => _count;
+
+  int increment() => ++_count;
+}
+`},
+	},
+
+	CleanArchitecture: {
+		{"lib/injection_container.dart", `
+import 'package:get_it/get_it.dart';
+import 'package:[[.Package]]/features/counter/data/datasources/counter_local_data_source.dart';
+import 'package:[[.Package]]/features/counter/data/repositories/counter_repository_impl.dart';
+import 'package:[[.Package]]/features/counter/domain/repositories/counter_repository.dart';
+import 'package:[[.Package]]/features/counter/domain/usecases/get_counter.dart';
+import 'package:[[.Package]]/features/counter/domain/usecases/increment_counter.dart';
+
+final sl = GetIt.instance;
+
+void init() {
+  sl
+    // Domain
+    ..registerLazySingleton(() => GetCounter(sl()))
+    ..registerLazySingleton(() => IncrementCounter(sl()))
+    // Data
+    ..registerLazySingleton<CounterRepository>(
+      () => CounterRepositoryImpl(sl()),
+    )
+    ..registerLazySingleton<CounterLocalDataSource>(
+      CounterLocalDataSourceImpl.new,
+    );
+}
+`},
+
+		// Core
+		{"lib/core/usecases/usecase.dart", `
+abstract class UseCase<T, P> {
+  const UseCase();
+
+  Future<T> call(P params);
+}
+
+class NoParams {
+  const NoParams();
+}
+`},
+
+		// Domain layer
+		{"lib/features/counter/domain/entities/counter.dart", `
+class Counter {
+  const Counter({required this.value});
+
+  final int value;
+}
+`},
+		{"lib/features/counter/domain/repositories/counter_repository.dart", `
+import 'package:[[.Package]]/features/counter/domain/entities/counter.dart';
+
+abstract interface class CounterRepository {
+  Future<Counter> getCounter();
+
+  Future<Counter> saveCounter(Counter counter);
+}
+`},
+		{"lib/features/counter/domain/usecases/get_counter.dart", `
+import 'package:[[.Package]]/core/usecases/usecase.dart';
+import 'package:[[.Package]]/features/counter/domain/entities/counter.dart';
+import 'package:[[.Package]]/features/counter/domain/repositories/counter_repository.dart';
+
+class GetCounter extends UseCase<Counter, NoParams> {
+  const GetCounter(this.repository);
+
+  final CounterRepository repository;
+
+  @override
+  Future<Counter> call(NoParams params) => repository.getCounter();
+}
+`},
+		{"lib/features/counter/domain/usecases/increment_counter.dart", `
+import 'package:[[.Package]]/core/usecases/usecase.dart';
+import 'package:[[.Package]]/features/counter/domain/entities/counter.dart';
+import 'package:[[.Package]]/features/counter/domain/repositories/counter_repository.dart';
+
+class IncrementCounter extends UseCase<Counter, NoParams> {
+  const IncrementCounter(this.repository);
+
+  final CounterRepository repository;
+
+  @override
+  Future<Counter> call(NoParams params) async {
+    final counter = await repository.getCounter();
+    return repository.saveCounter(Counter(value: counter.value + 1));
+  }
+}
+`},
+
+		// Data layer
+		{"lib/features/counter/data/models/counter_model.dart", `
+[[if .Freezed]]
+import 'package:[[.Package]]/features/counter/domain/entities/counter.dart';
+import 'package:freezed_annotation/freezed_annotation.dart';
+
+part 'counter_model.freezed.dart';
+part 'counter_model.g.dart';
+
+@freezed
+abstract class CounterModel with _$CounterModel implements Counter {
+  const factory CounterModel({required int value}) = _CounterModel;
+
+  factory CounterModel.fromEntity(Counter counter) {
+    return CounterModel(value: counter.value);
+  }
+
+  factory CounterModel.fromJson(Map<String, dynamic> json) =>
+      _$CounterModelFromJson(json);
+}
+[[else]]
+import 'package:[[.Package]]/features/counter/domain/entities/counter.dart';
+
+class CounterModel extends Counter {
+  const CounterModel({required super.value});
+
+  factory CounterModel.fromEntity(Counter counter) {
+    return CounterModel(value: counter.value);
+  }
+
+  factory CounterModel.fromJson(Map<String, dynamic> json) {
+    return CounterModel(value: json['value'] as int);
+  }
+
+  Map<String, dynamic> toJson() => {'value': value};
+}
+[[end]]
+`},
+		{"lib/features/counter/data/datasources/counter_local_data_source.dart", `
+import 'package:[[.Package]]/features/counter/data/models/counter_model.dart';
+
+abstract interface class CounterLocalDataSource {
+  Future<CounterModel> getCounter();
+
+  Future<void> cacheCounter(CounterModel counter);
+}
+
+class CounterLocalDataSourceImpl implements CounterLocalDataSource {
+  CounterModel _counter = const CounterModel(value: 0);
+
+  @override
+  Future<CounterModel> getCounter() async => _counter;
+
+  @override
+  Future<void> cacheCounter(CounterModel counter) async {
+    _counter = counter;
+  }
+}
+`},
+		{"lib/features/counter/data/repositories/counter_repository_impl.dart", `
+import 'package:[[.Package]]/features/counter/data/datasources/counter_local_data_source.dart';
+import 'package:[[.Package]]/features/counter/data/models/counter_model.dart';
+import 'package:[[.Package]]/features/counter/domain/entities/counter.dart';
+import 'package:[[.Package]]/features/counter/domain/repositories/counter_repository.dart';
+
+class CounterRepositoryImpl implements CounterRepository {
+  const CounterRepositoryImpl(this.localDataSource);
+
+  final CounterLocalDataSource localDataSource;
+
+  @override
+  Future<Counter> getCounter() => localDataSource.getCounter();
+
+  @override
+  Future<Counter> saveCounter(Counter counter) async {
+    final model = CounterModel.fromEntity(counter);
+    await localDataSource.cacheCounter(model);
+    return model;
+  }
+}
+`},
+
+		// The default widget test pumps MyApp without registering the
+		// dependencies main does
+		{"test/widget_test.dart", `
+import 'package:flutter/material.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:[[.Package]]/injection_container.dart' as di;
+import 'package:[[.Package]]/main.dart';
+
+void main() {
+  setUpAll(di.init);
+
+  testWidgets('Counter increments smoke test', (tester) async {
+    await tester.pumpWidget(const MyApp());
+    await tester.pumpAndSettle();
+
+    expect(find.text('0'), findsOneWidget);
+
+    await tester.tap(find.byIcon(Icons.add));
+    await tester.pumpAndSettle();
+
+    expect(find.text('1'), findsOneWidget);
+  });
+}
+`},
+	},
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"gopkg.in/yaml.v3"
+)
+
+var presetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// preset holds the answers of the wizard a team shares between projects,
+// everything but the project name and path. Manifests record it as well.
+type preset struct {
+	Pattern          string   `yaml:"pattern" json:"pattern"`
+	StateManager     string   `yaml:"state_manager" json:"state_manager"`
+	Freezed          bool     `yaml:"freezed" json:"freezed"`
+	Monorepo         bool     `yaml:"monorepo" json:"monorepo"`
+	AddOns           []string `yaml:"add_ons" json:"add_ons"`
+	LintPreset       string   `yaml:"lint_preset,omitempty" json:"lint_preset,omitempty"`
+	LintFile         string   `yaml:"lint_file,omitempty" json:"lint_file,omitempty"`
+	CIProviders      []string `yaml:"ci,omitempty" json:"ci,omitempty"`
+	GitInit          bool     `yaml:"git" json:"git"`
+	GitBranch        string   `yaml:"git_branch,omitempty" json:"git_branch,omitempty"`
+	GitCommitMessage string   `yaml:"git_commit_message,omitempty" json:"git_commit_message,omitempty"`
+}
+
+// presetFile is the layout of presets.yaml, and of the files presets are
+// exported to.
+type presetFile struct {
+	Presets map[string]preset `yaml:"presets"`
+}
+
+func presetCommand(args []string) {
+	usage := func() {
+		fmt.Println("Usage: flutter-arch preset <command>")
+		fmt.Println()
+		fmt.Println("  save <name>                      ask the wizard questions and save the answers")
+		fmt.Println("  list                             list the saved presets")
+		fmt.Println("  delete <name>                    delete a preset")
+		fmt.Println("  export [--output file] [name...] write presets to a file, all of them by default")
+		fmt.Println("  import [--force] <file>          add the presets of an exported file")
+	}
+	if len(args) == 0 {
+		usage()
+		return
+	}
+
+	switch args[0] {
+	case "save":
+		presetSaveCommand(args[1:])
+	case "list":
+		presetListCommand()
+	case "delete":
+		presetDeleteCommand(args[1:])
+	case "export":
+		presetExportCommand(args[1:])
+	case "import":
+		presetImportCommand(args[1:])
+	default:
+		usage()
+	}
+}
+
+func presetSaveCommand(args []string) {
+	flags := flag.NewFlagSet("preset save", flag.ExitOnError)
+	force := flags.Bool("force", false, "replace the preset if it already exists")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch preset save [flags] <name>")
+		flags.PrintDefaults()
+	}
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return
+	}
+	name := positional[0]
+	if err := validatePresetName(name); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Println("Error reading presets:", err)
+		return
+	}
+	if _, ok := presets[name]; ok && !*force {
+		fmt.Printf("Preset %s already exists, pass --force to replace it.\n", name)
+		return
+	}
+
+	opts := defaultProjectOptions()
+	if err := askPresetSteps(&opts); err != nil {
+		if err == terminal.InterruptErr {
+			fmt.Println("Cancelled, nothing was saved.")
+			return
+		}
+		fmt.Println("Error:", err)
+		return
+	}
+
+	presets[name] = presetFromOptions(opts)
+	if err := savePresets(presets); err != nil {
+		fmt.Println("Error saving presets:", err)
+		return
+	}
+	fmt.Printf("Preset %s saved\n", name)
+}
+
+func presetListCommand() {
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Println("Error reading presets:", err)
+		return
+	}
+	if len(presets) == 0 {
+		fmt.Println("No presets saved yet, create one with flutter-arch preset save <name>.")
+		return
+	}
+	for _, name := range presetNames(presets) {
+		p := presets[name]
+		fmt.Printf("%s: %s, add-ons: %s\n", name, architectureName(p.Pattern, p.StateManager), noneIfEmpty(p.AddOns))
+	}
+}
+
+func presetDeleteCommand(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: flutter-arch preset delete <name>")
+		return
+	}
+	name := args[0]
+
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Println("Error reading presets:", err)
+		return
+	}
+	if _, ok := presets[name]; !ok {
+		fmt.Printf("Preset %s does not exist.\n", name)
+		return
+	}
+	delete(presets, name)
+	if err := savePresets(presets); err != nil {
+		fmt.Println("Error saving presets:", err)
+		return
+	}
+	fmt.Printf("Preset %s deleted\n", name)
+}
+
+func presetExportCommand(args []string) {
+	flags := flag.NewFlagSet("preset export", flag.ExitOnError)
+	output := flags.String("output", "", "file to write the presets to (standard output by default)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch preset export [flags] [name...]")
+		flags.PrintDefaults()
+	}
+	names := parseInterspersed(flags, args)
+
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Println("Error reading presets:", err)
+		return
+	}
+	exported := presets
+	if len(names) > 0 {
+		exported = make(map[string]preset)
+		for _, name := range names {
+			p, ok := presets[name]
+			if !ok {
+				fmt.Printf("Preset %s does not exist.\n", name)
+				return
+			}
+			exported[name] = p
+		}
+	}
+
+	content, err := encodePresets(exported)
+	if err != nil {
+		fmt.Println("Error encoding presets:", err)
+		return
+	}
+	if *output == "" {
+		fmt.Print(string(content))
+		return
+	}
+	if err := os.WriteFile(*output, content, 0644); err != nil {
+		fmt.Println("Error writing presets:", err)
+		return
+	}
+	fmt.Printf("%d presets exported to %s\n", len(exported), *output)
+}
+
+func presetImportCommand(args []string) {
+	flags := flag.NewFlagSet("preset import", flag.ExitOnError)
+	force := flags.Bool("force", false, "replace the presets that already exist")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch preset import [flags] <file>")
+		flags.PrintDefaults()
+	}
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return
+	}
+
+	imported, err := readPresetFile(positional[0])
+	if err != nil {
+		fmt.Println("Error reading presets:", err)
+		return
+	}
+	for _, name := range presetNames(imported) {
+		if err := validatePresetName(name); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := validatePreset(imported[name]); err != nil {
+			fmt.Printf("Preset %s is invalid: %v\n", name, err)
+			return
+		}
+	}
+
+	presets, err := loadPresets()
+	if err != nil {
+		fmt.Println("Error reading presets:", err)
+		return
+	}
+	count := 0
+	for _, name := range presetNames(imported) {
+		if _, ok := presets[name]; ok && !*force {
+			fmt.Printf("Preset %s already exists, skipped (pass --force to replace it).\n", name)
+			continue
+		}
+		presets[name] = imported[name]
+		count++
+	}
+	if err := savePresets(presets); err != nil {
+		fmt.Println("Error saving presets:", err)
+		return
+	}
+	fmt.Printf("%d presets imported\n", count)
+}
+
+// presetsPath returns the path of the presets file, under XDG_CONFIG_HOME or
+// ~/.config.
+func presetsPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "flutter-arch", "presets.yaml"), nil
+}
+
+// loadPresets reads the saved presets, none when the file does not exist yet.
+func loadPresets() (map[string]preset, error) {
+	file, err := presetsPath()
+	if err != nil {
+		return nil, err
+	}
+	presets, err := readPresetFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]preset), nil
+	}
+	return presets, err
+}
+
+func readPresetFile(file string) (map[string]preset, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var decoded presetFile
+	if err := yaml.Unmarshal(content, &decoded); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if decoded.Presets == nil {
+		decoded.Presets = make(map[string]preset)
+	}
+	return decoded.Presets, nil
+}
+
+func savePresets(presets map[string]preset) error {
+	file, err := presetsPath()
+	if err != nil {
+		return err
+	}
+	content, err := encodePresets(presets)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0644)
+}
+
+func encodePresets(presets map[string]preset) ([]byte, error) {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(presetFile{Presets: presets}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), enc.Close()
+}
+
+// loadPreset returns the saved preset called name, checked against the
+// supported answers.
+func loadPreset(name string) (preset, error) {
+	presets, err := loadPresets()
+	if err != nil {
+		return preset{}, err
+	}
+	p, ok := presets[name]
+	if !ok {
+		return preset{}, fmt.Errorf("preset %s does not exist, see flutter-arch preset list", name)
+	}
+	if err := validatePreset(p); err != nil {
+		return preset{}, fmt.Errorf("preset %s is invalid: %w", name, err)
+	}
+	return p, nil
+}
+
+func presetNames(presets map[string]preset) []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validatePresetName(name string) error {
+	if !presetNamePattern.MatchString(name) {
+		return fmt.Errorf("preset name %q must start with a letter or a digit and contain only letters, digits, '_', '.' and '-'", name)
+	}
+	return nil
+}
+
+// validatePreset checks that every answer of the preset is one the wizard
+// offers, so that a preset written by another version of the tool or by
+// hand does not generate a broken project.
+func validatePreset(p preset) error {
+	oneOf := func(what, value string, values []string) error {
+		for _, v := range values {
+			if v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown %s %q", what, value)
+	}
+	if err := oneOf("layout pattern", p.Pattern, patterns); err != nil {
+		return err
+	}
+	if err := oneOf("state manager", p.StateManager, stateManagers); err != nil {
+		return err
+	}
+	for _, addOn := range p.AddOns {
+		if err := oneOf("add-on", addOn, addOns); err != nil {
+			return err
+		}
+	}
+	if p.LintPreset != "" {
+		if err := oneOf("lint preset", p.LintPreset, lintPresets); err != nil {
+			return err
+		}
+		if p.LintPreset == CustomLints && p.LintFile == "" {
+			return errors.New("custom lint preset without lint_file")
+		}
+	}
+	for _, provider := range p.CIProviders {
+		if err := oneOf("CI pipeline", provider, ciProviders); err != nil {
+			return err
+		}
+	}
+	if p.Monorepo {
+		return checkMonorepo(p.StateManager)
+	}
+	return nil
+}
+
+func presetFromOptions(opts projectOptions) preset {
+	p := preset{
+		Pattern:      opts.Pattern,
+		StateManager: opts.StateManager,
+		Freezed:      opts.Freezed,
+		Monorepo:     opts.Monorepo,
+		AddOns:       opts.AddOns,
+		CIProviders:  opts.CIProviders,
+		GitInit:      opts.GitInit,
+	}
+	if hasAddOn(opts, LintsAddOn) {
+		p.LintPreset = opts.LintPreset
+		// The file is read again when the preset is used from another folder
+		if opts.LintFile != "" {
+			p.LintFile, _ = filepath.Abs(opts.LintFile)
+		}
+	}
+	if opts.GitInit {
+		p.GitBranch, p.GitCommitMessage = opts.GitBranch, opts.GitCommitMessage
+	}
+	return p
+}
+
+// applyPreset copies the answers of the preset over the options, keeping the
+// defaults for the ones it leaves empty.
+func applyPreset(opts *projectOptions, p preset) {
+	opts.Pattern, opts.StateManager = p.Pattern, p.StateManager
+	opts.Freezed = p.Freezed
+	opts.Monorepo = opts.Monorepo || p.Monorepo
+	opts.AddOns = p.AddOns
+	if p.LintPreset != "" {
+		opts.LintPreset, opts.LintFile = p.LintPreset, p.LintFile
+	}
+	opts.CIProviders = p.CIProviders
+	opts.GitInit = p.GitInit
+	if p.GitBranch != "" {
+		opts.GitBranch = p.GitBranch
+	}
+	if p.GitCommitMessage != "" {
+		opts.GitCommitMessage = p.GitCommitMessage
+	}
+}
+
+// askSavePreset asks for a preset name, confirming before it replaces an
+// existing preset, and saves the answers under it.
+func askSavePreset(opts projectOptions) error {
+	presets, err := loadPresets()
+	if err != nil {
+		return err
+	}
+
+	var name string
+	promptInput := &survey.Input{Message: "Enter the preset name:"}
+	err = survey.AskOne(promptInput, &name, survey.WithValidator(func(answer interface{}) error {
+		value, _ := answer.(string)
+		return validatePresetName(value)
+	}))
+	if err != nil {
+		return err
+	}
+
+	if _, ok := presets[name]; ok {
+		replace := false
+		promptConfirm := &survey.Confirm{Message: fmt.Sprintf("Preset %s already exists, replace it?", name)}
+		if err := survey.AskOne(promptConfirm, &replace); err != nil {
+			return err
+		}
+		if !replace {
+			return nil
+		}
+	}
+
+	presets[name] = presetFromOptions(opts)
+	if err := savePresets(presets); err != nil {
+		return err
+	}
+	fmt.Printf("Preset %s saved\n", name)
+	return nil
+}
@@ -0,0 +1,2121 @@
+package main
+
+// stateManagerSpecs holds the templates of every state manager. Each one
+// renders the state holder, the page showing the counter and main.dart for
+// any layout pattern: the pattern only changes where the files go, what the
+// state holder depends on and how it computes the count.
+var stateManagerSpecs = map[string]stateManagerSpec{
+	BLoC: {
+		Packages:       []string{"flutter_bloc", "bloc"},
+		Dir:            "bloc",
+		Suffix:         "Bloc",
+		ImmutableState: true,
+		Holder: `
+import 'package:bloc/bloc.dart';
+[[.Imports]]
+[[.StateImport]]
+
+sealed class CounterEvent {
+  const CounterEvent();
+}
+[[if .Async]]
+final class CounterStarted extends CounterEvent {
+  const CounterStarted();
+}
+[[end]]
+final class CounterIncrementPressed extends CounterEvent {
+  const CounterIncrementPressed();
+}
+
+class [[.Holder]] extends Bloc<CounterEvent, [[.StateType]]> {
+  [[.Holder]]([[.CtorParams]])[[.Initializers (printf "super(%s)" .InitialState)]] {
+[[- if .Async]]
+    on<CounterStarted>((event, emit) async => emit([[.Emit .Load]]));
+    on<CounterIncrementPressed>(
+      (event, emit) async => emit([[.Emit (.Next (.Count "state"))]]),
+    );
+    add(const CounterStarted());
+[[- else]]
+    on<CounterIncrementPressed>((event, emit) => emit([[.Emit (.Next (.Count "state"))]]));
+[[- end]]
+  }
+
+[[.Fields]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_bloc/flutter_bloc.dart';
+import '[[.HolderURI]]';
+[[.StateImport]]
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: BlocBuilder<[[.Holder]], [[.StateType]]>(
+          builder: (context, state) {
+            return Text('[[.Interpolate "state"]]');
+          },
+        ),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: () {
+          context.read<[[.Holder]]>().add(const CounterIncrementPressed());
+        },
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:flutter_bloc/flutter_bloc.dart';
+import '[[.HolderURI]]';
+[[if not .PageURI]][[.StateImport]][[end]]
+[[.WiringImports true]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return MaterialApp(
+      home: BlocProvider(
+        create: (_) => [[.Holder]]([[.Args "di."]]),
+        child: const [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	Cubit: {
+		Packages:       []string{"flutter_bloc", "bloc"},
+		Dir:            "cubit",
+		Suffix:         "Cubit",
+		ImmutableState: true,
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:bloc/bloc.dart';
+[[.Imports]]
+[[.StateImport]]
+
+class [[.Holder]] extends Cubit<[[.StateType]]> {
+[[- if .Async]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers (printf "super(%s)" .InitialState)]] {
+    unawaited(load());
+  }
+[[- else]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers (printf "super(%s)" .InitialState)]];
+[[- end]]
+
+[[.Fields]]
+
+[[if .Async]]
+  Future<void> load() async => emit([[.Emit .Load]]);
+
+  Future<void> increment() async => emit([[.Emit (.Next (.Count "state"))]]);
+[[- else]]
+  void increment() => emit([[.Emit (.Next (.Count "state"))]]);
+[[- end]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_bloc/flutter_bloc.dart';
+import '[[.HolderURI]]';
+[[.StateImport]]
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: BlocBuilder<[[.Holder]], [[.StateType]]>(
+          builder: (context, state) {
+            return Text('[[.Interpolate "state"]]');
+          },
+        ),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: context.read<[[.Holder]]>().increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:flutter_bloc/flutter_bloc.dart';
+import '[[.HolderURI]]';
+[[if not .PageURI]][[.StateImport]][[end]]
+[[.WiringImports true]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return MaterialApp(
+      home: BlocProvider(
+        create: (_) => [[.Holder]]([[.Args "di."]]),
+        child: const [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	Provider: {
+		Packages:  []string{"provider"},
+		Dir:       "provider",
+		Suffix:    "Provider",
+		RoleNamed: true,
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:flutter/foundation.dart';
+[[.Imports]]
+
+class [[.Holder]] extends ChangeNotifier {
+[[- if .Async]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]] {
+    unawaited(load());
+  }
+[[- else if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  int _count = 0;
+
+  int get count => _count;
+[[if .Async]]
+  Future<void> load() async {
+    _count = [[.Load]];
+    notifyListeners();
+  }
+
+  Future<void> increment() async {
+[[- else]]
+  void increment() {
+[[- end]]
+    _count = [[.Next "_count"]];
+    notifyListeners();
+  }
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:provider/provider.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.Var]] = context.watch<[[.Holder]]>();
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Text('${[[.Var]].count}'),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: [[.Var]].increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:provider/provider.dart';
+import '[[.HolderURI]]';
+[[.WiringImports true]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return MultiProvider(
+      providers: [
+        ChangeNotifierProvider(create: (_) => [[.Holder]]([[.Args "di."]])),
+      ],
+      child: const MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	Riverpod: {
+		Packages:       []string{"flutter_riverpod"},
+		Dir:            "notifier",
+		Suffix:         "Notifier",
+		ImmutableState: true,
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+[[.Imports]]
+[[.WiringImports false]]
+[[.StateImport]]
+
+final counterProvider = NotifierProvider<[[.Holder]], [[.StateType]]>(
+  [[.New ""]],
+);
+
+class [[.Holder]] extends Notifier<[[.StateType]]> {
+[[- if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  @override
+  [[.StateType]] build() {
+    [[if .Async]]unawaited(load());[[end]]
+    return [[.InitialState]];
+  }
+[[if .Async]]
+  Future<void> load() async => state = [[.Emit .Load]];
+
+  Future<void> increment() async => state = [[.Emit (.Next (.Count "state"))]];
+[[- else]]
+  void increment() => state = [[.Emit (.Next (.Count "state"))]];
+[[- end]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends ConsumerWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context, WidgetRef ref) {
+    final count = ref.watch(
+      counterProvider[[if .StateClass]].select((state) => state.count)[[end]],
+    );
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Text('$count'),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: ref.read(counterProvider.notifier).increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[else]]import '[[.HolderURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return const ProviderScope(
+      child: MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	GetX: {
+		Packages: []string{"get"},
+		Dir:      "controller",
+		Suffix:   "Controller",
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:get/get.dart';
+[[.Imports]]
+
+class [[.Holder]] extends GetxController {
+[[- if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  final count = 0.obs;
+[[if .Async]]
+  @override
+  void onInit() {
+    super.onInit();
+    unawaited(load());
+  }
+
+  Future<void> load() async => count.value = [[.Load]];
+
+  Future<void> increment() async => count.value = [[.Next "count.value"]];
+[[- else]]
+  void increment() => count.value = [[.Next "count.value"]];
+[[- end]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:get/get.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.Var]] = Get.find<[[.Holder]]>();
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Obx(() {
+          return Text('${[[.Var]].count}');
+        }),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: [[.Var]].increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:get/get.dart';
+import '[[.HolderURI]]';
+[[.WiringImports true]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return GetMaterialApp(
+      initialBinding: BindingsBuilder.put([[.New "di."]]),
+      home: const [[.Page]](),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	MobX: {
+		Packages:    []string{"flutter_mobx", "mobx", "provider"},
+		DevPackages: []string{"build_runner", "mobx_codegen"},
+		Dir:         "store",
+		Suffix:      "Store",
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:mobx/mobx.dart';
+[[.Imports]]
+
+part '[[.HolderBase]].g.dart';
+
+class [[.Holder]] = _[[.Holder]] with _$[[.Holder]];
+
+abstract class _[[.Holder]] with Store {
+[[- if .Async]]
+  _[[.Holder]]([[.CtorParams]])[[.Initializers ""]] {
+    unawaited(load());
+  }
+[[- else if .HasDeps]]
+  _[[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  @observable
+  int count = 0;
+[[if .Async]]
+  @action
+  Future<void> load() async {
+    count = [[.Load]];
+  }
+
+  @action
+  Future<void> increment() async {
+[[- else]]
+  @action
+  void increment() {
+[[- end]]
+    count = [[.Next "count"]];
+  }
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_mobx/flutter_mobx.dart';
+import 'package:provider/provider.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.Var]] = context.read<[[.Holder]]>();
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Observer(
+          builder: (_) => Text('${[[.Var]].count}'),
+        ),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: [[.Var]].increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+[[if not .PageURI]]import 'package:flutter_mobx/flutter_mobx.dart';[[end]]
+import 'package:provider/provider.dart';
+import '[[.HolderURI]]';
+[[.WiringImports true]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return MultiProvider(
+      providers: [
+        Provider<[[.Holder]]>(create: (_) => [[.Holder]]([[.Args "di."]])),
+      ],
+      child: const MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	Redux: {
+		Packages:       []string{"redux", "flutter_redux"},
+		Dir:            "redux",
+		Suffix:         "Reducer",
+		ImmutableState: true,
+		Holder: `
+[[if .HasDeps]]import 'package:redux/redux.dart';[[end]]
+[[.Imports]]
+[[.StateImport]]
+
+sealed class CounterAction {
+  const CounterAction();
+}
+[[if .Async]]
+final class CounterLoadRequested extends CounterAction {
+  const CounterLoadRequested();
+}
+[[end]]
+final class CounterIncrementRequested extends CounterAction {
+  const CounterIncrementRequested();
+}
+[[if .HasDeps]]
+final class CounterChanged extends CounterAction {
+  const CounterChanged(this.count);
+
+  final int count;
+}
+
+[[.StateType]] counterReducer([[.StateType]] state, dynamic action) {
+  if (action is CounterChanged) {
+    return [[.Emit "action.count"]];
+  }
+  return state;
+}
+
+Middleware<[[.StateType]]> counterMiddleware([[.CtorParams]]) {
+  return (store, action, next) [[if .Async]]async [[end]]{
+    next(action);
+[[- if .Async]]
+    if (action is CounterLoadRequested) {
+      store.dispatch(CounterChanged([[.LoadParam]]));
+    }
+[[- end]]
+    if (action is CounterIncrementRequested) {
+      store.dispatch(CounterChanged([[.NextParam (.Count "store.state")]]));
+    }
+  };
+}
+[[else]]
+[[.StateType]] counterReducer([[.StateType]] state, dynamic action) {
+  if (action is CounterIncrementRequested) {
+    return [[.Emit (.NextParam (.Count "state"))]];
+  }
+  return state;
+}
+[[end]]
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_redux/flutter_redux.dart';
+import '[[.HolderURI]]';
+[[.StateImport]]
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: StoreConnector<[[.StateType]], String>(
+          converter: (store) => [[.Count "store.state"]].toString(),
+          builder: (context, count) {
+            return Text(count);
+          },
+        ),
+      ),
+      floatingActionButton: StoreConnector<[[.StateType]], VoidCallback>(
+        converter: (store) {
+          return () => store.dispatch(const CounterIncrementRequested());
+        },
+        builder: (context, callback) {
+          return FloatingActionButton(
+            onPressed: callback,
+            child: const Icon(Icons.add),
+          );
+        },
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:flutter_redux/flutter_redux.dart';
+import 'package:redux/redux.dart';
+import '[[.HolderURI]]';
+[[.StateImport]]
+[[.WiringImports true]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatefulWidget {
+  const MyApp({super.key});
+
+  @override
+  State<MyApp> createState() => _MyAppState();
+}
+
+class _MyAppState extends State<MyApp> {
+  final store = Store<[[.StateType]]>(
+    counterReducer,
+    initialState: [[.InitialState]],
+    [[if .HasDeps]]middleware: [counterMiddleware([[.Args "di."]])],[[end]]
+  );
+[[if .Async]]
+  @override
+  void initState() {
+    super.initState();
+    store.dispatch(const CounterLoadRequested());
+  }
+[[end]]
+  @override
+  Widget build(BuildContext context) {
+    return StoreProvider<[[.StateType]]>(
+      store: store,
+      child: const MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	ScopedModel: {
+		Packages:  []string{"scoped_model"},
+		Dir:       "scoped_model",
+		Suffix:    "Model",
+		RoleNamed: true,
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:scoped_model/scoped_model.dart';
+[[.Imports]]
+
+class [[.Holder]] extends Model {
+[[- if .Async]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]] {
+    unawaited(load());
+  }
+[[- else if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  int _count = 0;
+
+  int get count => _count;
+[[if .Async]]
+  Future<void> load() async {
+    _count = [[.Load]];
+    notifyListeners();
+  }
+
+  Future<void> increment() async {
+[[- else]]
+  void increment() {
+[[- end]]
+    _count = [[.Next "_count"]];
+    notifyListeners();
+  }
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:scoped_model/scoped_model.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: ScopedModelDescendant<[[.Holder]]>(
+          builder: (context, child, model) {
+            return Text('${model.count}');
+          },
+        ),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: ScopedModel.of<[[.Holder]]>(context).increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:scoped_model/scoped_model.dart';
+import '[[.HolderURI]]';
+[[.WiringImports true]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatefulWidget {
+  const MyApp({super.key});
+
+  @override
+  State<MyApp> createState() => _MyAppState();
+}
+
+class _MyAppState extends State<MyApp> {
+  final model = [[.Holder]]([[.Args "di."]]);
+
+  @override
+  Widget build(BuildContext context) {
+    return ScopedModel<[[.Holder]]>(
+      model: model,
+      child: const MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	StatesRebuilder: {
+		Packages:  []string{"states_rebuilder"},
+		Dir:       "states_rebuilder",
+		Suffix:    "Service",
+		RoleNamed: true,
+		Holder: `
+import 'package:states_rebuilder/states_rebuilder.dart';
+[[.Imports]]
+[[.WiringImports false]]
+
+final counterRM = RM.inject([[.New ""]]);
+
+class [[.Holder]] {
+[[- if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  int count = 0;
+[[if .Async]]
+  Future<void> load() async => count = [[.Load]];
+
+  Future<void> increment() async => count = [[.Next "count"]];
+[[- else]]
+  void increment() => count = [[.Next "count"]];
+[[- end]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:states_rebuilder/states_rebuilder.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: OnBuilder(
+          listenTo: counterRM,
+[[- if .Async]]
+          sideEffects: SideEffects(
+            initState: () => counterRM.setState((s) => s.load()),
+          ),
+[[- end]]
+          builder: () => Text('${counterRM.state.count}'),
+        ),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: () => counterRM.setState((s) => s.increment()),
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+[[if not .PageURI]]import 'package:states_rebuilder/states_rebuilder.dart';[[end]]
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[else]]import '[[.HolderURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return const MaterialApp(
+      home: [[.Page]](),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	MvcPattern: {
+		Packages: []string{"mvc_pattern"},
+		Dir:      "controller",
+		Suffix:   "Controller",
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:mvc_pattern/mvc_pattern.dart';
+[[.Imports]]
+
+class [[.Holder]] extends ControllerMVC {
+[[- if .Async]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]] {
+    unawaited(load());
+  }
+[[- else if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+[[- if and .PageURI .HasDeps (not .DI)]]
+
+  [[.Holder]].withDefaults() : this([[.Args ""]]);
+[[- end]]
+
+[[.Fields]]
+
+  int _count = 0;
+
+  int get count => _count;
+[[if .Async]]
+  Future<void> load() async {
+    final count = [[.Load]];
+    setState(() => _count = count);
+  }
+
+  Future<void> increment() async {
+[[- else]]
+  void increment() {
+[[- end]]
+    final count = [[.Next "_count"]];
+    setState(() => _count = count);
+  }
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:mvc_pattern/mvc_pattern.dart';
+import '[[.HolderURI]]';
+[[if .DI]][[.WiringImports false]][[end]]
+
+[[end]]
+class [[.Page]] extends StatefulWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  State<[[.Page]]> createState() => _[[.Page]]State();
+}
+
+class _[[.Page]]State extends StateMVC<[[.Page]]> {
+  _[[.Page]]State() : super([[if and .PageURI .HasDeps (not .DI)]][[.Holder]].withDefaults()[[else]][[.Holder]]([[.Args ""]])[[end]]) {
+    con = controller! as [[.Holder]];
+  }
+
+  late final [[.Holder]] con;
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Text('${con.count}'),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: con.increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[else]]
+import 'package:mvc_pattern/mvc_pattern.dart';
+import '[[.HolderURI]]';
+[[.WiringImports false]]
+[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return const MaterialApp(
+      home: [[.Page]](),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	Stacked: {
+		Packages:    []string{"stacked", "stacked_services"},
+		DevPackages: []string{"build_runner", "stacked_generator"},
+		Dir:         "viewmodels",
+		Suffix:      "ViewModel",
+		RoleNamed:   true,
+		Holder: `
+import 'package:stacked/stacked.dart';
+[[.Imports]]
+[[if .DI]][[.WiringImports false]][[else if .HasDeps]]import 'package:[[.Package]]/app/app.locator.dart';[[end]]
+
+class [[.Holder]] extends BaseViewModel {
+[[- range .Deps]]
+  final _[[.Name]] = [[if $.DI]]sl[[else]]locator[[end]]<[[.Type]]>();
+[[- end]]
+
+  int _count = 0;
+
+  int get count => _count;
+[[if .Async]]
+  Future<void> load() async {
+    _count = [[.Load]];
+    rebuildUi();
+  }
+
+  Future<void> increment() async {
+[[- else]]
+  void increment() {
+[[- end]]
+    _count = [[.Next "_count"]];
+    rebuildUi();
+  }
+}
+`,
+		Page: `
+[[if .PageURI]]
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:flutter/material.dart';
+import 'package:stacked/stacked.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StackedView<[[.Holder]]> {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget builder(BuildContext context, [[.Holder]] viewModel, Widget? child) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Text('${viewModel.count}'),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: viewModel.increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+
+  @override
+  [[.Holder]] viewModelBuilder(BuildContext context) => [[.Holder]]();
+[[if .Async]]
+  @override
+  void onViewModelReady([[.Holder]] viewModel) => unawaited(viewModel.load());
+[[end]]
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:[[.Package]]/app/app.locator.dart';
+import 'package:[[.Package]]/app/app.router.dart';
+import 'package:stacked_services/stacked_services.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if not .PageURI]]
+import 'package:stacked/stacked.dart';
+import '[[.HolderURI]]';
+[[end]]
+
+Future<void> main() async {
+  [[if .DI]]di.init();[[end]]
+  WidgetsFlutterBinding.ensureInitialized();
+  await setupLocator();
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return MaterialApp(
+      initialRoute: Routes.[[.PageVar]],
+      onGenerateRoute: StackedRouter().onGenerateRoute,
+      navigatorKey: StackedService.navigatorKey,
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+		Files: []generatedFile{
+			// stacked_generator writes app.locator.dart and app.router.dart
+			// next to it
+			{"lib/app/app.dart", `
+import 'package:stacked/stacked_annotations.dart';
+import 'package:stacked_services/stacked_services.dart';
+[[if .PageURI]]import '[[.PageURI]]';[[else]]import 'package:[[.Package]]/main.dart';[[end]]
+[[if not .DI]][[range .Deps]]import 'package:[[$.Package]]/[[.URI]]';
+[[end]][[end]]
+
+@StackedApp(
+  routes: [
+    MaterialRoute(page: [[.Page]], initial: true),
+  ],
+  dependencies: [
+    LazySingleton(classType: NavigationService),
+[[- if not .DI]][[range .Deps]]
+    LazySingleton(classType: [[.Type]]),
+[[- end]][[end]]
+  ],
+)
+class App {}
+`},
+			// The view models get their dependencies from the locator, which
+			// the default widget test does not set up
+			{"test/widget_test.dart", `
+import 'package:flutter/material.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:[[.Package]]/app/app.locator.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+import 'package:[[.Package]]/main.dart';
+
+void main() {
+[[- if .DI]]
+  setUpAll(() async {
+    di.init();
+    await setupLocator();
+  });
+[[- else]]
+  setUpAll(setupLocator);
+[[- end]]
+
+  testWidgets('Counter increments smoke test', (tester) async {
+    await tester.pumpWidget(const MyApp());
+    await tester.pumpAndSettle();
+
+    expect(find.text('0'), findsOneWidget);
+
+    await tester.tap(find.byIcon(Icons.add));
+    await tester.pumpAndSettle();
+
+    expect(find.text('1'), findsOneWidget);
+  });
+}
+`},
+		},
+	},
+
+	FlutterModular: {
+		Packages:  []string{"flutter_modular"},
+		Dir:       "stores",
+		Suffix:    "Store",
+		RoleNamed: true,
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:flutter/foundation.dart';
+[[.Imports]]
+
+class [[.Holder]] extends ChangeNotifier {
+[[- if .Async]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]] {
+    unawaited(load());
+  }
+[[- else if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  int _count = 0;
+
+  int get count => _count;
+[[if .Async]]
+  Future<void> load() async {
+    _count = [[.Load]];
+    notifyListeners();
+  }
+
+  Future<void> increment() async {
+[[- else]]
+  void increment() {
+[[- end]]
+    _count = [[.Next "_count"]];
+    notifyListeners();
+  }
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_modular/flutter_modular.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    final [[.Var]] = Modular.get<[[.Holder]]>();
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: ListenableBuilder(
+          listenable: [[.Var]],
+          builder: (context, _) => Text('${[[.Var]].count}'),
+        ),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: [[.Var]].increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:flutter_modular/flutter_modular.dart';
+import 'package:[[.Package]]/app/app_module.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if not .PageURI]]import '[[.HolderURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(ModularApp(module: AppModule(), child: const MyApp()));
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return MaterialApp.router(
+      routerConfig: Modular.routerConfig,
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+		Files: []generatedFile{
+			// The app module shares the dependencies of the sample with the
+			// feature modules and mounts them on their routes
+			{"lib/app/app_module.dart", `
+import 'package:flutter_modular/flutter_modular.dart';
+import 'package:[[.Package]]/app/counter_module.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[range .Deps]]import 'package:[[$.Package]]/[[.URI]]';
+[[end]]
+
+class AppModule extends Module {
+[[- if .HasDeps]]
+  @override
+  void exportedBinds(Injector i) {
+[[- range .Deps]]
+[[- if $.DI]]
+    i.addInstance<[[.Type]]>(di.sl());
+[[- else]]
+    i.addLazySingleton([[.Type]].new);
+[[- end]]
+[[- end]]
+  }
+[[end]]
+  @override
+  void routes(RouteManager r) {
+    r.module('/', module: CounterModule());
+  }
+}
+`},
+			{"lib/app/counter_module.dart", `
+import 'package:flutter_modular/flutter_modular.dart';
+[[if .HasDeps]]import 'package:[[.Package]]/app/app_module.dart';[[end]]
+import '[[.HolderURI]]';
+[[if .PageURI]]import '[[.PageURI]]';[[else]]import 'package:[[.Package]]/main.dart';[[end]]
+
+class CounterModule extends Module {
+[[- if .HasDeps]]
+  @override
+  List<Module> get imports => [AppModule()];
+[[end]]
+  @override
+  void binds(Injector i) {
+    i.addLazySingleton<[[.Holder]]>(
+      [[.Holder]].new,
+      config: BindConfig(onDispose: ([[.Var]]) => [[.Var]].dispose()),
+    );
+  }
+
+  @override
+  void routes(RouteManager r) {
+    r.child('/', child: (_) => const [[.Page]]());
+  }
+}
+`},
+			// The pages look up their stores in the modules, the app has to be
+			// pumped inside the ModularApp
+			{"test/widget_test.dart", `
+import 'package:flutter/material.dart';
+import 'package:flutter_modular/flutter_modular.dart';
+import 'package:flutter_test/flutter_test.dart';
+import 'package:[[.Package]]/app/app_module.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+import 'package:[[.Package]]/main.dart';
+
+void main() {
+[[- if .DI]]
+  setUpAll(di.init);
+[[end]]
+  testWidgets('Counter increments smoke test', (tester) async {
+    await tester.pumpWidget(ModularApp(module: AppModule(), child: const MyApp()));
+    await tester.pumpAndSettle();
+
+    expect(find.text('0'), findsOneWidget);
+
+    await tester.tap(find.byIcon(Icons.add));
+    await tester.pumpAndSettle();
+
+    expect(find.text('1'), findsOneWidget);
+  });
+}
+`},
+		},
+	},
+
+	RiverpodGenerator: {
+		Packages:       []string{"flutter_riverpod", "riverpod_annotation"},
+		DevPackages:    []string{"build_runner", "riverpod_generator", "custom_lint", "riverpod_lint"},
+		Dir:            "controllers",
+		Suffix:         "Controller",
+		RoleNamed:      true,
+		ImmutableState: true,
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:riverpod_annotation/riverpod_annotation.dart';
+[[.Imports]]
+[[.WiringImports false]]
+[[.StateImport]]
+
+part '[[.HolderBase]].g.dart';
+[[range .Deps]]
+@Riverpod(keepAlive: true)
+[[.Type]] [[.Var]](Ref ref) => [[if $.DI]]sl()[[else]][[.Type]]()[[end]];
+[[end]]
+@riverpod
+class [[.Holder]] extends _$[[.Holder]] {
+[[- range .Deps]]
+  [[.Type]] get _[[.Name]] => ref.read([[.Var]]Provider);
+[[- end]]
+
+  @override
+  [[.StateType]] build() {
+    [[if .Async]]unawaited(load());[[end]]
+    return [[.InitialState]];
+  }
+[[if .Async]]
+  Future<void> load() async => state = [[.Emit .Load]];
+
+  Future<void> increment() async => state = [[.Emit (.Next (.Count "state"))]];
+[[- else]]
+  void increment() => state = [[.Emit (.Next (.Count "state"))]];
+[[- end]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends ConsumerWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context, WidgetRef ref) {
+    final count = ref.watch(
+      [[.Var]]Provider[[if .StateClass]].select((state) => state.count)[[end]],
+    );
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Text('$count'),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: ref.read([[.Var]]Provider.notifier).increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:flutter_riverpod/flutter_riverpod.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[else]]import '[[.HolderURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return const ProviderScope(
+      child: MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+	},
+
+	HooksRiverpod: {
+		Packages:       []string{"hooks_riverpod", "flutter_hooks"},
+		Dir:            "notifier",
+		Suffix:         "Notifier",
+		ImmutableState: true,
+		Holder: `
+import 'package:hooks_riverpod/hooks_riverpod.dart';
+[[.Imports]]
+[[.WiringImports false]]
+[[.StateImport]]
+
+final counterProvider = NotifierProvider<[[.Holder]], [[.StateType]]>(
+  [[.New ""]],
+);
+
+class [[.Holder]] extends Notifier<[[.StateType]]> {
+[[- if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  @override
+  [[.StateType]] build() => [[.InitialState]];
+[[if .Async]]
+  Future<void> load() async => state = [[.Emit .Load]];
+
+  Future<void> increment() async => state = [[.Emit (.Next (.Count "state"))]];
+[[- else]]
+  void increment() => state = [[.Emit (.Next (.Count "state"))]];
+[[- end]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:flutter/material.dart';
+import 'package:flutter_hooks/flutter_hooks.dart';
+import 'package:hooks_riverpod/hooks_riverpod.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends HookConsumerWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context, WidgetRef ref) {
+    final count = ref.watch(
+      counterProvider[[if .StateClass]].select((state) => state.count)[[end]],
+    );
+    // The notifier is read when the button is pressed, not on every build
+    final increment = useCallback(
+      () => [[if .Async]]unawaited([[end]]ref.read(counterProvider.notifier).increment()[[if .Async]])[[end]],
+      const [],
+    );
+[[- if .Async]]
+    useEffect(() {
+      unawaited(ref.read(counterProvider.notifier).load());
+      return null;
+    }, const []);
+[[- end]]
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Text('$count'),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+[[if and .Async (not .PageURI)]]import 'dart:async';[[end]]
+
+import 'package:flutter/material.dart';
+[[if not .PageURI]]import 'package:flutter_hooks/flutter_hooks.dart';[[end]]
+import 'package:hooks_riverpod/hooks_riverpod.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[else]]import '[[.HolderURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return const ProviderScope(
+      child: MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+		Files: []generatedFile{
+			{"test/counter_notifier_test.dart", `
+import 'package:flutter_test/flutter_test.dart';
+import 'package:hooks_riverpod/hooks_riverpod.dart';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+import '[[.HolderURI]]';
+
+void main() {
+[[- if .DI]]
+  setUpAll(di.init);
+[[end]]
+  test('starts at zero', () {
+    final container = ProviderContainer();
+    addTearDown(container.dispose);
+
+    expect(container.read(counterProvider)[[if .StateClass]].count[[end]], 0);
+  });
+
+  test('increment adds one to the count', () [[if .Async]]async [[end]]{
+    final container = ProviderContainer();
+    addTearDown(container.dispose);
+
+    [[if .Async]]await [[end]]container.read(counterProvider.notifier).increment();
+
+    expect(container.read(counterProvider)[[if .StateClass]].count[[end]], 1);
+  });
+}
+`},
+		},
+	},
+
+	Signals: {
+		Packages: []string{"signals"},
+		Dir:      "signals",
+		Suffix:   "Store",
+		Holder: `
+[[if .Async]]import 'dart:async';[[end]]
+
+import 'package:signals/signals.dart';
+[[.Imports]]
+[[.WiringImports false]]
+
+final [[.Var]] = [[.Holder]]([[.Args ""]]);
+
+class [[.Holder]] {
+[[- if .Async]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]] {
+    unawaited(load());
+  }
+[[- else if .HasDeps]]
+  [[.Holder]]([[.CtorParams]])[[.Initializers ""]];
+[[- end]]
+
+[[.Fields]]
+
+  final _count = signal(0);
+
+  ReadonlySignal<int> get count => _count;
+
+  late final ReadonlySignal<String> label = computed(() => '${_count.value}');
+[[if .Async]]
+  Future<void> load() async => _count.value = [[.Load]];
+
+  Future<void> increment() async => _count.value = [[.Next "_count.value"]];
+[[- else]]
+  void increment() => _count.value = [[.Next "_count.value"]];
+[[- end]]
+}
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:signals/signals_flutter.dart';
+import '[[.HolderURI]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: Watch((context) => Text([[.Var]].label.value)),
+      ),
+      floatingActionButton: FloatingActionButton(
+        onPressed: [[.Var]].increment,
+        child: const Icon(Icons.add),
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+[[if not .PageURI]]import 'package:signals/signals_flutter.dart';[[end]]
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[else]]import '[[.HolderURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatelessWidget {
+  const MyApp({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return const MaterialApp(
+      home: [[.Page]](),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+		Files: []generatedFile{
+			// Each test builds its own store, the app shares the top-level one
+			{"test/counter_store_test.dart", `
+import 'package:flutter_test/flutter_test.dart';
+[[.WiringImports true]]
+import '[[.HolderURI]]';
+
+void main() {
+[[- if .DI]]
+  setUpAll(di.init);
+[[end]]
+  test('label follows the count', () [[if .Async]]async [[end]]{
+    final store = [[.Holder]]([[.Args "di."]]);
+[[- if .Async]]
+    await store.load();
+[[- end]]
+    expect(store.label.value, '0');
+
+    [[if .Async]]await [[end]]store.increment();
+
+    expect(store.count.value, 1);
+    expect(store.label.value, '1');
+  });
+}
+`},
+		},
+	},
+
+	ReduxThunk: {
+		Packages: []string{"redux", "flutter_redux", "redux_thunk"},
+		Dir:      "redux",
+		Suffix:   "Reducer",
+		Holder: `
+import 'package:redux/redux.dart';
+import '[[.SiblingURI "app_state.dart"]]';
+import '[[.SiblingURI "counter_actions.dart"]]';
+
+AppState appReducer(AppState state, dynamic action) {
+  return state.copyWith(count: countReducer(state.count, action));
+}
+
+final countReducer = TypedReducer<int, CounterChanged>(
+  (_, action) => action.count,
+).call;
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:flutter/material.dart';
+import 'package:flutter_redux/flutter_redux.dart';
+import '[[.SiblingURI "app_state.dart"]]';
+import '[[.SiblingURI "counter_actions.dart"]]';
+import '[[.SiblingURI "counter_selectors.dart"]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: StoreConnector<AppState, String>(
+          converter: (store) => selectCountLabel(store.state),
+          builder: (context, label) {
+            return Text(label);
+          },
+        ),
+      ),
+      floatingActionButton: StoreConnector<AppState, VoidCallback>(
+        converter: (store) {
+          return () => store.dispatch(incrementCounter);
+        },
+        builder: (context, callback) {
+          return FloatingActionButton(
+            onPressed: callback,
+            child: const Icon(Icons.add),
+          );
+        },
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:flutter/material.dart';
+import 'package:flutter_redux/flutter_redux.dart';
+import 'package:redux/redux.dart';
+import 'package:redux_thunk/redux_thunk.dart';
+import '[[.HolderURI]]';
+import '[[.SiblingURI "app_state.dart"]]';
+[[if or .Async (not .PageURI)]]import '[[.SiblingURI "counter_actions.dart"]]';[[end]]
+[[if not .PageURI]]import '[[.SiblingURI "counter_selectors.dart"]]';[[end]]
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatefulWidget {
+  const MyApp({super.key});
+
+  @override
+  State<MyApp> createState() => _MyAppState();
+}
+
+class _MyAppState extends State<MyApp> {
+  final store = Store<AppState>(
+    appReducer,
+    initialState: const AppState(),
+    middleware: [thunkMiddleware],
+  );
+[[if .Async]]
+  @override
+  void initState() {
+    super.initState();
+    store.dispatch(loadCounter);
+  }
+[[end]]
+  @override
+  Widget build(BuildContext context) {
+    return StoreProvider<AppState>(
+      store: store,
+      child: const MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+		Files: []generatedFile{
+			{"{dir}/app_state.dart", reduxAppStateTemplate},
+			// Thunks run the side effects of the counter, the reducers only
+			// apply the typed actions they dispatch
+			{"{dir}/counter_actions.dart", `
+import 'package:redux/redux.dart';
+import '[[.SiblingURI "app_state.dart"]]';
+[[if not .HasDeps]]import '[[.SiblingURI "counter_selectors.dart"]]';[[end]]
+[[.Imports]]
+[[.WiringImports false]]
+[[if not .Async]]
+// Stands for the time saving the count would take, the sample has no
+// asynchronous storage to wait for
+const incrementDelay = Duration(milliseconds: 200);
+[[end]]
+final class CounterChanged {
+  const CounterChanged(this.count);
+
+  final int count;
+}
+[[if .HasDeps]]
+final [[.DepsType]] counterDependencies = ([[.Args ""]]);
+[[end]]
+[[- if .Async]]
+Future<void> loadCounter(Store<AppState> store) async {
+  store.dispatch(CounterChanged([[.LoadVia "counterDependencies"]]));
+}
+[[end]]
+Future<void> incrementCounter(Store<AppState> store) async {
+[[- if not .Async]]
+  await Future<void>.delayed(incrementDelay);
+[[- end]]
+  store.dispatch(CounterChanged([[.NextVia "counterDependencies" "selectCount(store.state)"]]));
+}
+`},
+			{"{dir}/counter_selectors.dart", `
+import '[[.SiblingURI "app_state.dart"]]';
+
+int selectCount(AppState state) => state.count;
+
+String selectCountLabel(AppState state) => '${selectCount(state)}';
+`},
+			{"test/counter_reducer_test.dart", `
+import 'package:flutter_test/flutter_test.dart';
+import 'package:redux/redux.dart';
+import 'package:redux_thunk/redux_thunk.dart';
+import '[[.HolderURI]]';
+import '[[.SiblingURI "app_state.dart"]]';
+import '[[.SiblingURI "counter_actions.dart"]]';
+import '[[.SiblingURI "counter_selectors.dart"]]';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+
+void main() {
+[[- if .DI]]
+  setUpAll(di.init);
+[[end]]
+  test('appReducer applies CounterChanged', () {
+    final state = appReducer(const AppState(), const CounterChanged(3));
+
+    expect(selectCount(state), 3);
+    expect(selectCountLabel(state), '3');
+  });
+
+  test('incrementCounter dispatches the next count', () async {
+    final store = Store<AppState>(
+      appReducer,
+      initialState: const AppState(),
+      middleware: [thunkMiddleware],
+    );
+
+    await store.dispatch(incrementCounter);
+
+    expect(selectCount(store.state), 1);
+  });
+}
+`},
+			// The default widget test does not wait for the increment
+			{"test/widget_test.dart", `
+import 'package:flutter/material.dart';
+import 'package:flutter_test/flutter_test.dart';
+[[if not .Async]]import '[[.SiblingURI "counter_actions.dart"]]';[[end]]
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+import 'package:[[.Package]]/main.dart';
+
+void main() {
+[[- if .DI]]
+  setUpAll(di.init);
+[[end]]
+  testWidgets('Counter increments smoke test', (tester) async {
+    await tester.pumpWidget(const MyApp());
+    await tester.pumpAndSettle();
+
+    expect(find.text('0'), findsOneWidget);
+
+    await tester.tap(find.byIcon(Icons.add));
+[[- if .Async]]
+    await tester.pumpAndSettle();
+[[- else]]
+    await tester.pump(incrementDelay);
+[[- end]]
+
+    expect(find.text('1'), findsOneWidget);
+  });
+}
+`},
+		},
+	},
+
+	AsyncRedux: {
+		Packages: []string{"async_redux"},
+		Dir:      "redux",
+		Suffix:   "Actions",
+		Holder: `
+import 'package:async_redux/async_redux.dart';
+import '[[.SiblingURI "app_state.dart"]]';
+[[.Imports]]
+[[.WiringImports false]]
+[[if .HasDeps]]
+final [[.DepsType]] counterDependencies = ([[.Args ""]]);
+[[end]]
+[[- if .Async]]
+class LoadCounterAction extends ReduxAction<AppState> {
+  @override
+  Future<AppState?> reduce() async => state.copyWith(count: [[.LoadVia "counterDependencies"]]);
+}
+
+class IncrementCounterAction extends ReduxAction<AppState> {
+  @override
+  Future<AppState?> reduce() async => state.copyWith(count: [[.NextVia "counterDependencies" "state.count"]]);
+}
+[[- else]]
+class IncrementCounterAction extends ReduxAction<AppState> {
+  @override
+  AppState? reduce() => state.copyWith(count: [[.NextVia "counterDependencies" "state.count"]]);
+}
+[[- end]]
+`,
+		Page: `
+[[if .PageURI]]
+import 'package:async_redux/async_redux.dart';
+import 'package:flutter/material.dart';
+import '[[.HolderURI]]';
+import '[[.SiblingURI "app_state.dart"]]';
+
+[[end]]
+class [[.Page]] extends StatelessWidget {
+  const [[.Page]]({super.key});
+
+  @override
+  Widget build(BuildContext context) {
+    return Scaffold(
+      appBar: AppBar(
+        title: const Text('[[.Title]]'),
+      ),
+      body: Center(
+        child: StoreConnector<AppState, int>(
+          converter: (store) => store.state.count,
+          builder: (context, count) {
+            return Text('$count');
+          },
+        ),
+      ),
+      floatingActionButton: StoreConnector<AppState, VoidCallback>(
+        converter: (store) {
+          return () => store.dispatch(IncrementCounterAction());
+        },
+        builder: (context, callback) {
+          return FloatingActionButton(
+            onPressed: callback,
+            child: const Icon(Icons.add),
+          );
+        },
+      ),
+    );
+  }
+}
+`,
+		App: `
+import 'package:async_redux/async_redux.dart';
+import 'package:flutter/material.dart';
+import '[[.SiblingURI "app_state.dart"]]';
+[[if or .Async (not .PageURI)]]import '[[.HolderURI]]';[[end]]
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+[[if .PageURI]]import '[[.PageURI]]';[[end]]
+
+void main() {
+  [[if .DI]]di.init();[[end]]
+  runApp(const MyApp());
+}
+
+class MyApp extends StatefulWidget {
+  const MyApp({super.key});
+
+  @override
+  State<MyApp> createState() => _MyAppState();
+}
+
+class _MyAppState extends State<MyApp> {
+  final store = Store<AppState>(initialState: const AppState());
+[[if .Async]]
+  @override
+  void initState() {
+    super.initState();
+    store.dispatch(LoadCounterAction());
+  }
+[[end]]
+  @override
+  Widget build(BuildContext context) {
+    return StoreProvider<AppState>(
+      store: store,
+      child: const MaterialApp(
+        home: [[.Page]](),
+      ),
+    );
+  }
+}
+
+[[if not .PageURI]][[template "page" .]][[end]]
+`,
+		Files: []generatedFile{
+			{"{dir}/app_state.dart", reduxAppStateTemplate},
+			{"test/counter_actions_test.dart", `
+import 'package:async_redux/async_redux.dart';
+import 'package:flutter_test/flutter_test.dart';
+import '[[.HolderURI]]';
+import '[[.SiblingURI "app_state.dart"]]';
+[[if .DI]]import 'package:[[.Package]]/injection_container.dart' as di;[[end]]
+
+void main() {
+[[- if .DI]]
+  setUpAll(di.init);
+[[end]]
+[[- if .Async]]
+  test('LoadCounterAction loads the stored count', () async {
+    final store = Store<AppState>(initialState: const AppState(count: 5));
+
+    await store.dispatchAndWait(LoadCounterAction());
+
+    expect(store.state.count, 0);
+  });
+[[end]]
+  test('IncrementCounterAction adds one to the count', () async {
+    final store = Store<AppState>(initialState: const AppState());
+
+    await store.dispatchAndWait(IncrementCounterAction());
+
+    expect(store.state.count, 1);
+  });
+}
+`},
+		},
+	},
+}
+
+// reduxAppStateTemplate is the state of the Redux Thunk and async_redux
+// stores, the counter being one slice of it.
+const reduxAppStateTemplate = `
+import 'package:flutter/foundation.dart';
+
+@immutable
+class AppState {
+  const AppState({this.count = 0});
+
+  final int count;
+
+  AppState copyWith({int? count}) => AppState(count: count ?? this.count);
+}
+`
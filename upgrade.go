@@ -0,0 +1,239 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func upgradeCommand(args []string) {
+	flags := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	project := flags.String("project", ".", "path of the project, the folder holding "+manifestFile)
+	dryRun := flags.Bool("dry-run", false, "report what would change without writing any file")
+	force := flags.Bool("force", false, "upgrade even when the project already uses the current templates")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: flutter-arch upgrade [flags]")
+		flags.PrintDefaults()
+	}
+	if positional := parseInterspersed(flags, args); len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+
+	rootPath := *project
+	old, err := readManifest(rootPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	if !*force && old.Version == toolVersion && !templatesChanged(old.Templates) {
+		fmt.Printf("%s already uses the templates of flutter-arch %s.\n", old.ProjectName, toolVersion)
+		return
+	}
+
+	// Generate the project again with the current templates, next to it
+	fmt.Printf("Generating %s again with flutter-arch %s...\n", old.ProjectName, toolVersion)
+	tmp, upgraded, err := generateAgain(old)
+	if tmp != "" {
+		defer os.RemoveAll(tmp)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	newRoot := filepath.Join(tmp, old.ProjectName)
+
+	var paths []string
+	for file := range upgraded.Files {
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+
+	var updated, merged, added, conflicted, kept []string
+	for _, file := range paths {
+		theirs, err := os.ReadFile(filepath.Join(newRoot, filepath.FromSlash(file)))
+		if err != nil {
+			fmt.Println("Error reading upgraded file:", err)
+			return
+		}
+		target := filepath.Join(rootPath, filepath.FromSlash(file))
+		ours, err := os.ReadFile(target)
+		exists := err == nil
+		if exists && path.Base(file) == "pubspec.yaml" {
+			theirs = []byte(keepVersionConstraints(string(theirs), string(ours)))
+			if _, ok := upgraded.Base[file]; ok {
+				upgraded.Base[file] = string(theirs)
+			}
+			upgraded.Files[file] = hashContent(theirs)
+		}
+
+		var content string
+		switch {
+		case exists && string(ours) == string(theirs):
+			continue
+		case exists && old.Base[file] == string(theirs) && old.Files[file] == hashContent(theirs):
+			// The templates did not change it, whatever the user did
+			continue
+		case !exists:
+			if _, generated := old.Files[file]; generated {
+				// Deleted by the user, leave it deleted
+				kept = append(kept, file)
+				continue
+			}
+			content = string(theirs)
+			added = append(added, file)
+		case old.Files[file] == hashContent(ours):
+			// Unchanged since it was generated, take the new version
+			content = string(theirs)
+			updated = append(updated, file)
+		default:
+			// A file the project had before it was generated has no base, its
+			// differences with the new version all conflict
+			result := merge3(old.Base[file], string(ours), string(theirs),
+				"current", "generated by flutter-arch "+old.Version, "templates of flutter-arch "+toolVersion)
+			content = result.Content
+			if result.Conflicts > 0 {
+				conflicted = append(conflicted, fmt.Sprintf("%s (%d conflicting changes)", file, result.Conflicts))
+			} else {
+				merged = append(merged, file)
+			}
+		}
+
+		if *dryRun {
+			continue
+		}
+		os.MkdirAll(filepath.Dir(target), 0755)
+		createFile(target, content)
+	}
+
+	// Files the templates do not generate anymore are left to the user
+	for file := range old.Files {
+		if _, ok := upgraded.Files[file]; !ok {
+			if _, err := os.Stat(filepath.Join(rootPath, filepath.FromSlash(file))); err == nil {
+				kept = append(kept, file)
+			}
+		}
+	}
+	sort.Strings(kept)
+
+	report := func(title string, files []string) {
+		if len(files) == 0 {
+			return
+		}
+		fmt.Println(title)
+		for _, file := range files {
+			fmt.Println("  " + file)
+		}
+	}
+	fmt.Println()
+	if *dryRun {
+		fmt.Println("Dry run, no file was written.")
+	}
+	report("Updated:", updated)
+	report("Added:", added)
+	report("Merged with your changes:", merged)
+	report("Conflicts, resolve the <<<<<<< markers:", conflicted)
+	report("Deleted or not generated anymore, left as is:", kept)
+	if len(updated)+len(added)+len(merged)+len(conflicted) == 0 {
+		fmt.Println("No file needs to change.")
+	}
+	if *dryRun {
+		return
+	}
+
+	// The new templates output is the base of the next upgrade
+	if err := saveManifest(rootPath, upgraded); err != nil {
+		fmt.Println("Error writing manifest:", err)
+		return
+	}
+	fmt.Printf("%s upgraded to the templates of flutter-arch %s\n", old.ProjectName, toolVersion)
+}
+
+// generateAgain generates the project of the manifest with the current
+// templates in a temporary folder, which the caller removes, and returns the
+// folder and the manifest of the new project.
+func generateAgain(manifest projectManifest) (string, projectManifest, error) {
+	if err := validatePreset(manifest.Options); err != nil {
+		return "", projectManifest{}, fmt.Errorf("the manifest options are not supported anymore: %w", err)
+	}
+	tmp, err := os.MkdirTemp("", "flutter-arch-")
+	if err != nil {
+		return "", projectManifest{}, err
+	}
+
+	opts := defaultProjectOptions()
+	applyPreset(&opts, manifest.Options)
+	opts.ProjectName, opts.Path, opts.GitInit = manifest.ProjectName, tmp, false
+	if err := initializeProject(opts); err != nil {
+		return tmp, projectManifest{}, err
+	}
+
+	generated, err := readManifest(filepath.Join(tmp, manifest.ProjectName))
+	if err != nil {
+		return tmp, projectManifest{}, fmt.Errorf("generating the project: %w", err)
+	}
+	// Only the copy skipped git, the project keeps its answers
+	generated.Options.GitInit = manifest.Options.GitInit
+	generated.Options.GitBranch = manifest.Options.GitBranch
+	generated.Options.GitCommitMessage = manifest.Options.GitCommitMessage
+	return tmp, generated, nil
+}
+
+// keepVersionConstraints replaces the constraints of the packages and SDKs
+// theirs lists with the ones ours already has. flutter create and pub add
+// write the latest versions, which are not a change of the templates.
+func keepVersionConstraints(theirs, ours string) string {
+	constraints := make(map[string]string)
+	forEachConstraint(ours, func(key, line string) string {
+		constraints[key] = line
+		return line
+	})
+	return forEachConstraint(theirs, func(key, line string) string {
+		if kept, ok := constraints[key]; ok {
+			return kept
+		}
+		return line
+	})
+}
+
+// forEachConstraint calls replace with the lines of a pubspec.yaml giving a
+// version constraint in environment, dependencies or dev_dependencies, keyed
+// by section and package, and returns the content with their replacements.
+func forEachConstraint(content string, replace func(key, line string) string) string {
+	lines := strings.Split(content, "\n")
+	section := ""
+	for i, line := range lines {
+		if !strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "" {
+			section = strings.TrimSuffix(strings.TrimSpace(line), ":")
+			continue
+		}
+		if section != "environment" && section != "dependencies" && section != "dev_dependencies" {
+			continue
+		}
+		if !strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "   ") {
+			continue
+		}
+		// Dependencies given as a map, such as sdk: flutter, have no value
+		name, value, ok := strings.Cut(strings.TrimSpace(strings.TrimRight(line, "\r")), ":")
+		if !ok || strings.TrimSpace(value) == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		lines[i] = replace(section+"/"+name, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// templatesChanged reports whether any template set recorded in a manifest
+// has a newer version.
+func templatesChanged(templates map[string]int) bool {
+	for set, version := range templates {
+		if templateVersions[set] != version {
+			return true
+		}
+	}
+	return false
+}
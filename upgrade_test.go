@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestKeepVersionConstraints(t *testing.T) {
+	ours := `name: demo
+
+environment:
+  sdk: ^3.5.0
+
+dependencies:
+  flutter:
+    sdk: flutter
+  provider: ^6.1.0
+  # http: ^1.0.0
+
+dev_dependencies:
+  flutter_lints: ^4.0.0
+`
+	theirs := `name: demo
+
+environment:
+  sdk: ^3.6.1
+
+dependencies:
+  flutter:
+    sdk: flutter
+  provider: ^6.1.2
+  get_it: ^8.0.3
+
+dev_dependencies:
+  flutter_lints: ^5.0.0
+  mocktail: ^1.0.4
+`
+	want := `name: demo
+
+environment:
+  sdk: ^3.5.0
+
+dependencies:
+  flutter:
+    sdk: flutter
+  provider: ^6.1.0
+  get_it: ^8.0.3
+
+dev_dependencies:
+  flutter_lints: ^4.0.0
+  mocktail: ^1.0.4
+`
+	if got := keepVersionConstraints(theirs, ours); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateAgainKeepsGitOptions(t *testing.T) {
+	useFakeFlutter(t)
+
+	manifest := projectManifest{
+		ProjectName: "demo",
+		Options: preset{
+			Pattern:          patterns[0],
+			StateManager:     stateManagers[0],
+			GitInit:          true,
+			GitBranch:        "trunk",
+			GitCommitMessage: "Scaffold",
+		},
+	}
+	tmp, generated, err := generateAgain(manifest)
+	if tmp != "" {
+		t.Cleanup(func() { os.RemoveAll(tmp) })
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := generated.Options; !got.GitInit || got.GitBranch != "trunk" || got.GitCommitMessage != "Scaffold" {
+		t.Errorf("git options = %v %q %q, want true \"trunk\" \"Scaffold\"", got.GitInit, got.GitBranch, got.GitCommitMessage)
+	}
+}
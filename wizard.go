@@ -0,0 +1,495 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// Choices of the summary screen
+const (
+	createChoice string = "Create the project"
+	changeChoice string = "Go back and change an answer"
+	saveChoice   string = "Save the answers as a preset"
+	cancelChoice string = "Cancel"
+)
+
+var errWizardCancelled = errors.New("wizard cancelled")
+
+var dartPackageNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// wizardStep asks a group of related questions. Steps read their defaults
+// from the options, so that asking one again starts from the previous
+// answers.
+type wizardStep struct {
+	Name string
+	// Preset reports whether presets hold the answers of the step
+	Preset bool
+	// Skip reports whether the step does not apply to the answers so far
+	Skip func(opts projectOptions) bool
+	Ask  func(opts *projectOptions) error
+}
+
+var wizardSteps = []wizardStep{
+	{Name: "Architecture", Preset: true, Ask: askArchitecture},
+	{Name: "Project name and path", Ask: askProject},
+	{Name: "Add-ons", Preset: true, Ask: askAddOns},
+	{
+		Name:   "Lint preset",
+		Preset: true,
+		Skip:   func(opts projectOptions) bool { return !hasAddOn(opts, LintsAddOn) },
+		Ask:    askLints,
+	},
+	{
+		Name:   "CI pipelines",
+		Preset: true,
+		Skip:   func(opts projectOptions) bool { return !hasAddOn(opts, CIAddOn) },
+		Ask:    askCI,
+	},
+	{Name: "Git repository", Preset: true, Ask: askGit},
+}
+
+// runWizard asks every step, but the ones a preset answered, then shows the
+// summary of the answers until the user creates the project, going back to
+// any step in between, or cancels.
+func runWizard(opts *projectOptions, fromPreset bool) error {
+	asked := make(map[string]bool)
+	for _, step := range wizardSteps {
+		asked[step.Name] = fromPreset && step.Preset
+	}
+	if err := askSteps(opts, asked); err != nil {
+		return err
+	}
+
+	for {
+		// Drop the answers of the add-ons deselected since
+		if !hasAddOn(*opts, LintsAddOn) {
+			opts.LintPreset, opts.LintFile = FlutterLints, ""
+		}
+		if !hasAddOn(*opts, CIAddOn) {
+			opts.CIProviders = nil
+		}
+		// and the ones the state manager chosen since does not offer
+		if ownDIStateManagers[opts.StateManager] {
+			var kept []string
+			for _, addOn := range opts.AddOns {
+				if addOn != DIAddOn {
+					kept = append(kept, addOn)
+				}
+			}
+			opts.AddOns = kept
+		}
+		printSummary(*opts)
+
+		var choice string
+		prompt := &survey.Select{
+			Message: "Create the project?",
+			Options: []string{createChoice, changeChoice, saveChoice, cancelChoice},
+		}
+		if err := survey.AskOne(prompt, &choice); err != nil {
+			return err
+		}
+		switch choice {
+		case createChoice:
+			if targetExists(*opts) && opts.ExistingPolicy == AbortExisting {
+				fmt.Println("The project directory exists, change the project or how to handle it.")
+				continue
+			}
+			// A preset may pick Stacked while --monorepo was passed
+			if opts.Monorepo {
+				if err := checkMonorepo(opts.StateManager); err != nil {
+					fmt.Println(err.Error() + ", change the state manager.")
+					continue
+				}
+			}
+			return nil
+		case cancelChoice:
+			return errWizardCancelled
+		case saveChoice:
+			if err := askSavePreset(*opts); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var names []string
+		for _, step := range wizardSteps {
+			if step.Skip == nil || !step.Skip(*opts) {
+				names = append(names, step.Name)
+			}
+		}
+		var name string
+		prompt = &survey.Select{
+			Message: "Which answers do you want to change?",
+			Options: names,
+		}
+		if err := survey.AskOne(prompt, &name); err != nil {
+			return err
+		}
+
+		// Ask the step again, then the steps its new answers enable
+		asked[name] = false
+		if err := askSteps(opts, asked); err != nil {
+			return err
+		}
+	}
+}
+
+// askSteps asks the steps not asked yet that apply to the answers so far.
+func askSteps(opts *projectOptions, asked map[string]bool) error {
+	for _, step := range wizardSteps {
+		if asked[step.Name] || step.Skip != nil && step.Skip(*opts) {
+			continue
+		}
+		if err := step.Ask(opts); err != nil {
+			return err
+		}
+		asked[step.Name] = true
+	}
+	return nil
+}
+
+// askPresetSteps asks only the steps whose answers presets hold.
+func askPresetSteps(opts *projectOptions) error {
+	asked := make(map[string]bool)
+	for _, step := range wizardSteps {
+		asked[step.Name] = !step.Preset
+	}
+	if err := askSteps(opts, asked); err != nil {
+		return err
+	}
+	if !hasAddOn(*opts, LintsAddOn) {
+		opts.LintPreset, opts.LintFile = FlutterLints, ""
+	}
+	if !hasAddOn(*opts, CIAddOn) {
+		opts.CIProviders = nil
+	}
+	return nil
+}
+
+func askArchitecture(opts *projectOptions) error {
+	// Prompt the user to select a layout pattern
+	prompt := &survey.Select{
+		Message: "Choose the layout pattern you want to use for your Flutter project:",
+		Options: patterns,
+		Description: func(value string, index int) string {
+			return patternDescriptions[value]
+		},
+	}
+	if opts.Pattern != "" {
+		prompt.Default = opts.Pattern
+	}
+	if err := survey.AskOne(prompt, &opts.Pattern); err != nil {
+		return err
+	}
+
+	// Prompt the user to select a state manager, among those a monorepo can
+	// be split with when one is created
+	options := stateManagers
+	if opts.Monorepo {
+		options = nil
+		for _, stateManager := range stateManagers {
+			if checkMonorepo(stateManager) == nil {
+				options = append(options, stateManager)
+			}
+		}
+	}
+	prompt = &survey.Select{
+		Message: "Choose the state manager:",
+		Options: options,
+		Description: func(value string, index int) string {
+			return stateManagerDescriptions[value]
+		},
+	}
+	if opts.StateManager != "" && (!opts.Monorepo || checkMonorepo(opts.StateManager) == nil) {
+		prompt.Default = opts.StateManager
+	}
+	if err := survey.AskOne(prompt, &opts.StateManager); err != nil {
+		return err
+	}
+
+	// Prompt the user to generate models with freezed
+	promptConfirm := &survey.Confirm{
+		Message: "Generate immutable state and models with freezed and json_serializable?",
+		Default: opts.Freezed,
+	}
+	return survey.AskOne(promptConfirm, &opts.Freezed)
+}
+
+func askProject(opts *projectOptions) error {
+	// Prompt the user to enter the project name
+	promptInput := &survey.Input{
+		Message: "Enter the project name:",
+		Default: opts.ProjectName,
+	}
+	err := survey.AskOne(promptInput, &opts.ProjectName, survey.WithValidator(func(answer interface{}) error {
+		if name, _ := answer.(string); !dartPackageNamePattern.MatchString(name) {
+			return errors.New("use lowercase letters, digits and underscores, starting with a letter")
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	// Prompt the user to enter the path
+	promptInput = &survey.Input{
+		Message: "Enter the path to initialize the project (press Enter for current directory):",
+		Default: opts.Path,
+	}
+	err = survey.AskOne(promptInput, &opts.Path, survey.WithValidator(func(answer interface{}) error {
+		dir, _ := answer.(string)
+		if strings.TrimSpace(dir) == "" {
+			return nil
+		}
+		if info, err := os.Stat(strings.TrimSpace(dir)); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	opts.Path = strings.TrimSpace(opts.Path)
+	if opts.Path == "" {
+		opts.Path = "."
+	}
+	if !targetExists(*opts) {
+		return nil
+	}
+
+	// Prompt the user to choose how to handle the existing directory
+	prompt := &survey.Select{
+		Message: fmt.Sprintf("%s already exists in %s, what should be done with it?", opts.ProjectName, opts.Path),
+		Options: existingPolicies,
+		Default: AbortExisting,
+	}
+	if opts.ExistingPolicy != "" {
+		prompt.Default = opts.ExistingPolicy
+	}
+	return survey.AskOne(prompt, &opts.ExistingPolicy)
+}
+
+func askAddOns(opts *projectOptions) error {
+	// Prompt the user to select the add-ons
+	available := availableAddOns(opts.StateManager)
+	var selected []string
+	for _, addOn := range opts.AddOns {
+		for _, option := range available {
+			if addOn == option {
+				selected = append(selected, addOn)
+			}
+		}
+	}
+	promptMulti := &survey.MultiSelect{
+		Message: "Choose the add-ons to include:",
+		Options: available,
+		Default: selected,
+		Description: func(value string, index int) string {
+			return addOnDescriptions[value]
+		},
+	}
+	opts.AddOns = nil
+	return survey.AskOne(promptMulti, &opts.AddOns)
+}
+
+func askLints(opts *projectOptions) error {
+	// Prompt the user to select a lint preset
+	prompt := &survey.Select{
+		Message: "Choose the lint preset for analysis_options.yaml:",
+		Options: lintPresets,
+		Default: FlutterLints,
+	}
+	if opts.LintPreset != "" {
+		prompt.Default = opts.LintPreset
+	}
+	if err := survey.AskOne(prompt, &opts.LintPreset); err != nil {
+		return err
+	}
+
+	if opts.LintPreset != CustomLints {
+		return nil
+	}
+	promptInput := &survey.Input{
+		Message: "Enter the path to your analysis_options.yaml file:",
+		Default: opts.LintFile,
+	}
+	return survey.AskOne(promptInput, &opts.LintFile, survey.WithValidator(func(answer interface{}) error {
+		file, _ := answer.(string)
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			return fmt.Errorf("%s is not a file", file)
+		}
+		return nil
+	}))
+}
+
+func askCI(opts *projectOptions) error {
+	// Prompt the user to select the CI pipelines to generate
+	promptMulti := &survey.MultiSelect{
+		Message: "Choose the CI pipelines to generate:",
+		Options: ciProviders,
+		Default: opts.CIProviders,
+	}
+	opts.CIProviders = nil
+	return survey.AskOne(promptMulti, &opts.CIProviders, survey.WithValidator(survey.MinItems(1)))
+}
+
+func askGit(opts *projectOptions) error {
+	// Prompt the user to initialize a git repository
+	promptConfirm := &survey.Confirm{
+		Message: "Initialize a git repository with an initial commit?",
+		Default: opts.GitInit,
+	}
+	if err := survey.AskOne(promptConfirm, &opts.GitInit); err != nil {
+		return err
+	}
+	if !opts.GitInit {
+		return nil
+	}
+
+	promptInput := &survey.Input{
+		Message: "Enter the initial branch name:",
+		Default: opts.GitBranch,
+	}
+	if err := survey.AskOne(promptInput, &opts.GitBranch); err != nil {
+		return err
+	}
+
+	promptInput = &survey.Input{
+		Message: "Enter the initial commit message:",
+		Default: opts.GitCommitMessage,
+	}
+	return survey.AskOne(promptInput, &opts.GitCommitMessage)
+}
+
+// printSummary shows the answers and the files the project will get on top
+// of the ones flutter create generates.
+func printSummary(opts projectOptions) {
+	yesNo := map[bool]string{true: "yes", false: "no"}
+
+	fmt.Println()
+	fmt.Println("Summary")
+	fmt.Println("  Architecture: ", architectureName(opts.Pattern, opts.StateManager))
+	fmt.Println("  Freezed:      ", yesNo[opts.Freezed])
+	fmt.Println("  Project:      ", opts.ProjectName, "in", opts.Path)
+	if targetExists(opts) {
+		fmt.Println("  Existing:     ", opts.ExistingPolicy)
+	}
+	fmt.Println("  Monorepo:     ", yesNo[opts.Monorepo])
+	fmt.Println("  Add-ons:      ", noneIfEmpty(opts.AddOns))
+	if hasAddOn(opts, LintsAddOn) {
+		lints := opts.LintPreset
+		if lints == CustomLints {
+			lints = opts.LintFile
+		}
+		fmt.Println("  Lint preset:  ", lints)
+	}
+	if hasAddOn(opts, CIAddOn) {
+		fmt.Println("  CI pipelines: ", noneIfEmpty(opts.CIProviders))
+	}
+	if opts.GitInit {
+		fmt.Printf("  Git:           branch %s, commit %q\n", opts.GitBranch, opts.GitCommitMessage)
+	} else {
+		fmt.Println("  Git:          ", "no")
+	}
+
+	files, err := plannedFiles(opts)
+	if err != nil {
+		fmt.Println("Error rendering templates:", err)
+		return
+	}
+	fmt.Println()
+	fmt.Println("Planned files, on top of the ones of flutter create:")
+	fmt.Print(fileTree(opts.ProjectName, files))
+	if opts.Monorepo {
+		fmt.Println("The layers under lib/ then move to packages/.")
+	}
+	fmt.Println()
+}
+
+// noneIfEmpty joins the values, or returns "none" when there are none.
+func noneIfEmpty(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+// plannedFiles returns the paths, relative to the project root, of the files
+// the options generate.
+func plannedFiles(opts projectOptions) ([]string, error) {
+	rendered, err := renderArchitecture(opts.ProjectName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	app := ""
+	var files []string
+	if opts.Monorepo {
+		app = path.Join(monorepoAppsDir, opts.ProjectName)
+		files = append(files, "pubspec.yaml", "melos.yaml")
+	}
+	for _, file := range rendered {
+		files = append(files, path.Join(app, file.Path))
+	}
+	if hasAddOn(opts, LintsAddOn) {
+		files = append(files, path.Join(app, "analysis_options.yaml"))
+	}
+	for _, provider := range opts.CIProviders {
+		switch provider {
+		case GitHubActions:
+			files = append(files, ".github/workflows/ci.yaml")
+		case GitLabCI:
+			files = append(files, ".gitlab-ci.yml")
+		case ShellScript:
+			files = append(files, "ci.sh")
+		}
+	}
+	return files, nil
+}
+
+// fileTree draws the paths as a tree under root.
+func fileTree(root string, paths []string) string {
+	type node map[string]node
+	tree := node{}
+	for _, p := range paths {
+		current := tree
+		for _, part := range strings.Split(p, "/") {
+			if current[part] == nil {
+				current[part] = node{}
+			}
+			current = current[part]
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(root + "/\n")
+	var draw func(n node, prefix string)
+	draw = func(n node, prefix string) {
+		var names []string
+		for name := range n {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			branch, indent := "├── ", "│   "
+			if i == len(names)-1 {
+				branch, indent = "└── ", "    "
+			}
+			if len(n[name]) > 0 {
+				name += "/"
+			}
+			b.WriteString(prefix + branch + name + "\n")
+			draw(n[strings.TrimSuffix(name, "/")], prefix+indent)
+		}
+	}
+	draw(tree, "")
+	return b.String()
+}